@@ -0,0 +1,314 @@
+package integration
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	yaml "gopkg.in/yaml.v2"
+
+	"github.com/kubernetes-sigs/testing_frameworks/integration/internal"
+)
+
+// AdmissionWebhook serves a validating or mutating admission webhook from
+// within the test process, and registers it with the APIServer of a
+// ControlPlane. See ControlPlane.StartAdmissionWebhook for details.
+type AdmissionWebhook struct {
+	// Name is the name of the webhook, and of the
+	// ValidatingWebhookConfiguration or MutatingWebhookConfiguration it is
+	// registered with. It needs to be fully qualified, e.g.
+	// "pod-policy.example.com".
+	Name string
+
+	// Handler handles the AdmissionReview requests the APIServer sends.
+	Handler http.Handler
+
+	// Mutating registers the webhook as a mutating instead of a validating
+	// webhook.
+	Mutating bool
+
+	// Rules describe which requests the APIServer sends to the webhook.
+	Rules []AdmissionRule
+
+	// FailurePolicy defines how the APIServer handles errors calling the
+	// webhook, either "Fail" or "Ignore".
+	//
+	// If not specified, this defaults to "Fail".
+	FailurePolicy string
+
+	// StartTimeout specifies the time to wait for the APIServer to call the
+	// webhook, after it has been registered.
+	//
+	// If not specified, this defaults to 20 seconds.
+	StartTimeout time.Duration
+
+	server  *internal.TLSServer
+	dir     string
+	kubeCtl *KubeCtl
+
+	probeName string
+	probeOnce sync.Once
+	probed    chan struct{}
+}
+
+// AdmissionRule describes operations on resources which are sent to an
+// AdmissionWebhook, e.g.
+//
+//	AdmissionRule{
+//		Operations:  []string{"CREATE", "UPDATE"},
+//		APIGroups:   []string{"apps"},
+//		APIVersions: []string{"v1"},
+//		Resources:   []string{"deployments"},
+//	}
+type AdmissionRule struct {
+	Operations  []string `yaml:"operations"`
+	APIGroups   []string `yaml:"apiGroups"`
+	APIVersions []string `yaml:"apiVersions"`
+	Resources   []string `yaml:"resources"`
+}
+
+// probePath is the path the readiness probe of an AdmissionWebhook is
+// served on. It is not passed on to the user's Handler.
+const probePath = "/testing-framework-probe"
+
+// probeNamespace is the namespace the readiness probe of an AdmissionWebhook
+// tries to create its ConfigMap in.
+const probeNamespace = "default"
+
+// StartAdmissionWebhook serves the webhook's Handler over TLS on a free port,
+// registers it with the APIServer and waits until the APIServer actually
+// calls it.
+//
+// The certificate the webhook is served with is issued by a fresh CA, which
+// is added to the webhook configuration so the APIServer trusts it.
+//
+// To find out when the APIServer has picked up the configuration, another
+// webhook is added to the same configuration, which handles the creation of
+// ConfigMaps. It only denies the creation of a ConfigMap with a name unique to
+// the probe, and allows all others. As long as the APIServer does not call the
+// probe, that ConfigMap gets created, and is deleted again right away. Once
+// the probe has been called, it is removed from the configuration, as the
+// APIServer picks up the configuration as a whole.
+func (f *ControlPlane) StartAdmissionWebhook(webhook *AdmissionWebhook) (err error) {
+	if webhook.Name == "" {
+		return fmt.Errorf("expected the webhook to have a Name")
+	}
+	if webhook.Handler == nil {
+		return fmt.Errorf("expected the webhook to have a Handler")
+	}
+	if webhook.FailurePolicy == "" {
+		webhook.FailurePolicy = "Fail"
+	}
+	if webhook.StartTimeout == 0 {
+		webhook.StartTimeout = 20 * time.Second
+	}
+
+	webhook.kubeCtl = f.KubeCtl()
+	webhook.probed = make(chan struct{})
+
+	suffix := make([]byte, 4)
+	if _, err := rand.Read(suffix); err != nil {
+		return err
+	}
+	webhook.probeName = "webhook-probe-" + hex.EncodeToString(suffix)
+
+	webhook.dir, err = ioutil.TempDir("", "k8s_test_framework_")
+	if err != nil {
+		return err
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(probePath, webhook.serveProbe)
+	mux.Handle("/", webhook.Handler)
+	webhook.server, err = internal.StartTLSServer("admission-webhook", mux)
+	if err != nil {
+		os.RemoveAll(webhook.dir)
+		return err
+	}
+	defer func() {
+		if err != nil {
+			webhook.Stop()
+		}
+	}()
+
+	if err := webhook.register(true); err != nil {
+		return err
+	}
+	if err := webhook.waitForProbe(); err != nil {
+		return err
+	}
+
+	return webhook.register(false)
+}
+
+// URL returns the URL the webhook is served on.
+func (w *AdmissionWebhook) URL() string {
+	if w.server == nil {
+		return ""
+	}
+	return w.server.URL.String()
+}
+
+// Stop deregisters the webhook from the APIServer and stops serving it.
+func (w *AdmissionWebhook) Stop() error {
+	if w.server == nil {
+		return nil
+	}
+
+	if _, err := w.kubeCtl.runForOutput("delete", w.configurationKind(), w.Name, "--ignore-not-found"); err != nil {
+		return err
+	}
+	if err := w.server.Close(); err != nil {
+		return err
+	}
+	w.server = nil
+
+	return os.RemoveAll(w.dir)
+}
+
+func (w *AdmissionWebhook) configurationKind() string {
+	if w.Mutating {
+		return "MutatingWebhookConfiguration"
+	}
+	return "ValidatingWebhookConfiguration"
+}
+
+type webhookConfiguration struct {
+	APIVersion string            `yaml:"apiVersion"`
+	Kind       string            `yaml:"kind"`
+	Metadata   map[string]string `yaml:"metadata"`
+	Webhooks   []webhookEntry    `yaml:"webhooks"`
+}
+
+type webhookEntry struct {
+	Name          string              `yaml:"name"`
+	ClientConfig  webhookClientConfig `yaml:"clientConfig"`
+	Rules         []AdmissionRule     `yaml:"rules"`
+	FailurePolicy string              `yaml:"failurePolicy"`
+}
+
+type webhookClientConfig struct {
+	URL      string `yaml:"url"`
+	CABundle string `yaml:"caBundle"`
+}
+
+// register applies the webhook configuration, with the probe, if withProbe is
+// set.
+func (w *AdmissionWebhook) register(withProbe bool) error {
+	clientConfig := func(path string) webhookClientConfig {
+		return webhookClientConfig{
+			URL:      w.server.URL.String() + path,
+			CABundle: base64.StdEncoding.EncodeToString(w.server.CA.CertBytes()),
+		}
+	}
+
+	config := webhookConfiguration{
+		APIVersion: "admissionregistration.k8s.io/v1beta1",
+		Kind:       w.configurationKind(),
+		Metadata:   map[string]string{"name": w.Name},
+		Webhooks: []webhookEntry{{
+			Name:          w.Name,
+			ClientConfig:  clientConfig("/"),
+			Rules:         w.Rules,
+			FailurePolicy: w.FailurePolicy,
+		}},
+	}
+	if withProbe {
+		config.Webhooks = append(config.Webhooks, webhookEntry{
+			Name:         "probe." + w.Name,
+			ClientConfig: clientConfig(probePath),
+			Rules: []AdmissionRule{{
+				Operations:  []string{"CREATE"},
+				APIGroups:   []string{""},
+				APIVersions: []string{"v1"},
+				Resources:   []string{"configmaps"},
+			}},
+			// The probe must never get in the way of other ConfigMaps.
+			FailurePolicy: "Ignore",
+		})
+	}
+
+	content, err := yaml.Marshal(config)
+	if err != nil {
+		return err
+	}
+	configFile := filepath.Join(w.dir, "webhook-configuration.yaml")
+	if err := ioutil.WriteFile(configFile, content, 0600); err != nil {
+		return err
+	}
+
+	_, err = w.kubeCtl.runForOutput("apply", "--filename", configFile)
+	return err
+}
+
+func (w *AdmissionWebhook) waitForProbe() error {
+	timedOut := time.After(w.StartTimeout)
+	for {
+		// This is expected to fail, because the probe denied it. If it
+		// succeeds, the APIServer does not know about the webhook yet.
+		_, _, err := w.kubeCtl.Run("create", "configmap", w.probeName, "--namespace", probeNamespace)
+		if err == nil {
+			if _, err := w.kubeCtl.runForOutput(
+				"delete", "configmap", w.probeName, "--namespace", probeNamespace, "--ignore-not-found",
+			); err != nil {
+				return err
+			}
+		}
+
+		select {
+		case <-w.probed:
+			return nil
+		case <-timedOut:
+			return fmt.Errorf("timeout waiting for the APIServer to call webhook %s", w.Name)
+		case <-time.After(100 * time.Millisecond):
+		}
+	}
+}
+
+type admissionReview struct {
+	APIVersion string             `json:"apiVersion"`
+	Kind       string             `json:"kind"`
+	Request    *admissionRequest  `json:"request,omitempty"`
+	Response   *admissionResponse `json:"response,omitempty"`
+}
+
+type admissionRequest struct {
+	UID       string `json:"uid"`
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+}
+
+type admissionResponse struct {
+	UID     string            `json:"uid"`
+	Allowed bool              `json:"allowed"`
+	Status  map[string]string `json:"status,omitempty"`
+}
+
+func (w *AdmissionWebhook) serveProbe(rw http.ResponseWriter, req *http.Request) {
+	review := admissionReview{}
+	if err := json.NewDecoder(req.Body).Decode(&review); err != nil || review.Request == nil {
+		http.Error(rw, "expected an AdmissionReview request", http.StatusBadRequest)
+		return
+	}
+
+	response := &admissionResponse{UID: review.Request.UID, Allowed: true}
+	if review.Request.Name == w.probeName && review.Request.Namespace == probeNamespace {
+		w.probeOnce.Do(func() { close(w.probed) })
+		response.Allowed = false
+		response.Status = map[string]string{"message": "webhook probe of the testing framework"}
+	}
+
+	writeJSON(rw, admissionReview{
+		APIVersion: review.APIVersion,
+		Kind:       review.Kind,
+		Response:   response,
+	})
+}
@@ -0,0 +1,430 @@
+package integration
+
+import (
+	"encoding/base64"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/kubernetes-sigs/testing_frameworks/integration/internal"
+)
+
+// aggregatedAPIServerNamespace is the namespace of the Services the
+// APIServices of aggregated APIServers refer to.
+const aggregatedAPIServerNamespace = "kube-system"
+
+var (
+	apiServicesResource = GroupVersionResource{Group: "apiregistration.k8s.io", Version: "v1", Resource: "apiservices"}
+	servicesResource    = GroupVersionResource{Version: "v1", Resource: "services"}
+)
+
+// AggregatedAPIServer knows how to run an extension apiserver, e.g. one built
+// with k8s.io/apiserver, behind the aggregation layer of the APIServer. See
+// ControlPlane.StartAggregatedAPIServer for details.
+type AggregatedAPIServer struct {
+	// Name is the name of the binary, e.g. "wardle-apiserver", which is used
+	// to find it if Path is not specified, like the Name of a Process.
+	Name string
+
+	// Path is the path to the binary.
+	//
+	// If this is left as the empty string, we will attempt to locate a binary
+	// named Name. See the "Binaries" section above (in doc.go) for details.
+	Path string
+
+	// Args is a list of arguments which will passed to the binary. Before they
+	// are passed on, they will be evaluated as go-template strings. This means
+	// you can use fields which are defined and exported on this
+	// AggregatedAPIServer struct (e.g. "--secure-port={{ .SecureURL.Port }}"
+	// or "--requestheader-client-ca-file={{ .RequestHeaderCAFile }}").
+	// Those templates will be evaluated after the defaulting of the
+	// AggregatedAPIServer's fields has already happened and just before the
+	// binary actually gets started.
+	//
+	// If not specified, the arguments to serve securely, and to delegate
+	// authentication and authorization to the APIServer, which every
+	// k8s.io/apiserver based binary understands, will be used. Most binaries
+	// need more, e.g. "--etcd-servers", in which case all of them have to be
+	// specified.
+	Args []string
+
+	// Env is a list of environment variables, e.g. "LOG_LEVEL=debug", the
+	// binary is started with in addition to those of the test process, see
+	// Process.
+	Env []string
+
+	// Group is the API group the AggregatedAPIServer serves, e.g.
+	// "wardle.example.com".
+	Group string
+
+	// Versions are the versions of the Group the AggregatedAPIServer serves,
+	// e.g. "v1alpha1". An APIService is registered for each of them.
+	Versions []string
+
+	// SecureURL is the address the AggregatedAPIServer should listen on for
+	// TLS connections, from the APIServer and for its health checks. It is
+	// served with a certificate issued by a CA generated when the
+	// AggregatedAPIServer starts, which the APIServer is told to trust.
+	//
+	// If this is not specified, we default to a random free port on localhost.
+	SecureURL *url.URL
+
+	// CertDir is a path to a directory containing the AggregatedAPIServer's
+	// serving certificate, and the CAs it needs to trust.
+	//
+	// If left unspecified, then the ControlPlane will create a fresh temporary
+	// directory, and the Stop() method will clean it up.
+	CertDir string
+
+	// KubeConfigFile is the path to the kubeconfig the AggregatedAPIServer
+	// uses to connect to the APIServer.
+	//
+	// If this is not specified, the ControlPlane generates one, authenticating
+	// as a member of "system:masters" named after the AggregatedAPIServer.
+	KubeConfigFile string
+
+	// StartTimeout, StopTimeout specify the time the AggregatedAPIServer is
+	// allowed to take when starting and stopping before an error is emitted.
+	// StartTimeout includes the time for its APIServices to become Available.
+	//
+	// If not specified, these default to 20 seconds.
+	StartTimeout time.Duration
+	StopTimeout  time.Duration
+
+	// Out, Err specify where the AggregatedAPIServer should write its StdOut,
+	// StdErr to.
+	//
+	// If not specified, the output will be discarded.
+	Out io.Writer
+	Err io.Writer
+
+	process             *Process
+	ca                  *internal.TinyCA
+	client              *RESTClient
+	dirNeedsCleaning    bool
+	generatedKubeConfig bool
+}
+
+// StartAggregatedAPIServer starts the AggregatedAPIServer, registers it with
+// the APIServer and waits until all of its APIServices are Available, i.e.
+// until requests for its Group are proxied to it:
+//
+//	wardle := &integration.AggregatedAPIServer{
+//		Name:     "wardle-apiserver",
+//		Group:    "wardle.example.com",
+//		Versions: []string{"v1alpha1"},
+//	}
+//	err := cp.StartAggregatedAPIServer(wardle)
+//	defer wardle.Stop()
+//
+// The APIServer authenticates against it with a client certificate issued by
+// the APIServer's front proxy CA, and passes on the user a request is made
+// for in the "X-Remote-User" and "X-Remote-Group" headers. The
+// AggregatedAPIServer trusts that CA, and the APIServer's CA, by default.
+//
+// The APIServices refer to an ExternalName Service in "kube-system", which
+// points to the SecureURL. Neither of them is deleted by Reset. The
+// AggregatedAPIServer is stopped with the ControlPlane.
+func (f *ControlPlane) StartAggregatedAPIServer(server *AggregatedAPIServer) (err error) {
+	if f.dir == "" || f.APIServer == nil || f.APIServer.frontProxyCA == nil {
+		return fmt.Errorf("the ControlPlane needs to be started before it can start an AggregatedAPIServer")
+	}
+	if server.Name == "" && server.Path == "" {
+		return fmt.Errorf("expected the AggregatedAPIServer to have a Name or Path")
+	}
+	if server.Group == "" || len(server.Versions) == 0 {
+		return fmt.Errorf("expected the AggregatedAPIServer to have a Group and Versions")
+	}
+
+	server.client, err = f.adminRESTClient()
+	if err != nil {
+		return err
+	}
+	if server.StartTimeout == 0 {
+		server.StartTimeout = 20 * time.Second
+	}
+	if server.SecureURL == nil {
+		server.SecureURL, err = internal.NewLocalURL("https")
+		if err != nil {
+			return err
+		}
+	}
+	if server.CertDir == "" {
+		server.CertDir, err = ioutil.TempDir("", "k8s_test_framework_")
+		if err != nil {
+			return err
+		}
+		server.dirNeedsCleaning = true
+	} else if err := os.MkdirAll(server.CertDir, 0700); err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			server.Stop()
+		}
+	}()
+
+	process := &Process{Name: server.Name, Path: server.Path}
+	if server.KubeConfigFile == "" {
+		server.KubeConfigFile = filepath.Join(server.CertDir, "kubeconfig")
+		if err := f.writeKubeConfigAs(server.KubeConfigFile, process.user()); err != nil {
+			return err
+		}
+		server.generatedKubeConfig = true
+	}
+	if err := server.writeCerts(f.APIServer); err != nil {
+		return err
+	}
+
+	process.Args = internal.DoAggregatedAPIServerArgDefaulting(server.Args)
+	process.Env = server.Env
+	process.URL = server.SecureURL
+	process.HealthCheckEndpoint = "/healthz"
+	process.HealthCheckClient = healthCheckClient(server.ca)
+	process.Dir = server.CertDir
+	process.KubeConfigFile = server.KubeConfigFile
+	process.StartTimeout = server.StartTimeout
+	process.StopTimeout = server.StopTimeout
+	process.Out = server.Out
+	process.Err = server.Err
+	process.templateData = server
+	server.process = process
+	if err := process.Start(); err != nil {
+		return err
+	}
+	server.Path = process.Path
+	server.StopTimeout = process.StopTimeout
+
+	if err := server.register(); err != nil {
+		return err
+	}
+	if err := server.waitUntilAvailable(); err != nil {
+		return err
+	}
+	// If starting fails, the deferred Stop cleans up, so the ControlPlane
+	// only needs to know about AggregatedAPIServers which are running.
+	f.aggregatedAPIServers = append(f.aggregatedAPIServers, server)
+	return nil
+}
+
+// stopAggregatedAPIServers stops all AggregatedAPIServers started on the
+// ControlPlane.
+func (f *ControlPlane) stopAggregatedAPIServers() error {
+	for _, server := range f.aggregatedAPIServers {
+		if err := server.Stop(); err != nil {
+			return err
+		}
+	}
+	f.aggregatedAPIServers = nil
+	return nil
+}
+
+// ServingCertFile, ServingKeyFile return the paths to the certificate, and
+// its key, the AggregatedAPIServer serves with.
+func (s *AggregatedAPIServer) ServingCertFile() string {
+	return filepath.Join(s.CertDir, internal.AggregatedAPIServerServingCertFile)
+}
+
+func (s *AggregatedAPIServer) ServingKeyFile() string {
+	return filepath.Join(s.CertDir, internal.AggregatedAPIServerServingKeyFile)
+}
+
+// ClientCAFile returns the path to the certificate of the APIServer's CA,
+// which issues the client certificates of Users.
+func (s *AggregatedAPIServer) ClientCAFile() string {
+	return filepath.Join(s.CertDir, internal.AggregatedAPIServerClientCAFile)
+}
+
+// RequestHeaderCAFile returns the path to the certificate of the APIServer's
+// front proxy CA, see APIServer.FrontProxyCACertFile.
+func (s *AggregatedAPIServer) RequestHeaderCAFile() string {
+	return filepath.Join(s.CertDir, internal.AggregatedAPIServerRequestHeaderCAFile)
+}
+
+// Stop deregisters the AggregatedAPIServer from the APIServer, stops it, and
+// cleans up the CertDir if necessary.
+func (s *AggregatedAPIServer) Stop() error {
+	if s.client != nil {
+		for _, version := range s.Versions {
+			err := s.client.Delete(apiServicesResource, "", s.apiServiceName(version))
+			if err != nil && !IsNotFound(err) {
+				return err
+			}
+		}
+		err := s.client.Delete(servicesResource, aggregatedAPIServerNamespace, s.serviceName())
+		if err != nil && !IsNotFound(err) {
+			return err
+		}
+		s.client = nil
+	}
+	if s.process != nil {
+		if err := s.process.Stop(); err != nil {
+			return err
+		}
+		s.process = nil
+	}
+	if s.generatedKubeConfig {
+		s.KubeConfigFile = ""
+		s.generatedKubeConfig = false
+	}
+	if s.dirNeedsCleaning {
+		// Start over with a fresh directory, should it be started again.
+		dir := s.CertDir
+		s.CertDir = ""
+		s.dirNeedsCleaning = false
+		return os.RemoveAll(dir)
+	}
+	return nil
+}
+
+// writeCerts issues the serving certificate of the AggregatedAPIServer, for
+// the SecureURL and the name of its Service, which the APIServer verifies,
+// and copies the CAs it needs to trust from the APIServer.
+func (s *AggregatedAPIServer) writeCerts(apiServer *APIServer) error {
+	if s.ca == nil {
+		var err error
+		s.ca, err = internal.NewTinyCA("integration-aggregated-apiserver-ca")
+		if err != nil {
+			return err
+		}
+	}
+	err := internal.WriteServingCert(s.CertDir, s.ca,
+		internal.AggregatedAPIServerServingCertFile, internal.AggregatedAPIServerServingKeyFile,
+		s.SecureURL.Hostname(), s.serviceName()+"."+aggregatedAPIServerNamespace+".svc")
+	if err != nil {
+		return err
+	}
+
+	frontProxyCA, err := ioutil.ReadFile(apiServer.FrontProxyCACertFile())
+	if err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(s.RequestHeaderCAFile(), frontProxyCA, 0600); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(s.ClientCAFile(), apiServer.ca.CertBytes(), 0600)
+}
+
+// register creates the Service pointing to the SecureURL, and an APIService
+// for each of the Versions, replacing those of an earlier run.
+func (s *AggregatedAPIServer) register() error {
+	port, err := net.LookupPort("tcp", s.SecureURL.Port())
+	if err != nil {
+		return err
+	}
+
+	// An ExternalName Service is resolved to its name and the port of the
+	// APIService, not to Endpoints, which must not be on localhost.
+	externalName := s.SecureURL.Hostname()
+	if ip := net.ParseIP(externalName); ip != nil && ip.To4() == nil {
+		externalName = "localhost"
+	}
+	err = s.recreate(servicesResource, aggregatedAPIServerNamespace, Object{
+		"apiVersion": "v1",
+		"kind":       "Service",
+		"metadata":   map[string]interface{}{"name": s.serviceName()},
+		"spec": map[string]interface{}{
+			"type":         "ExternalName",
+			"externalName": externalName,
+			"ports":        []interface{}{map[string]interface{}{"port": port}},
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, version := range s.Versions {
+		err := s.recreate(apiServicesResource, "", Object{
+			"apiVersion": "apiregistration.k8s.io/v1",
+			"kind":       "APIService",
+			"metadata":   map[string]interface{}{"name": s.apiServiceName(version)},
+			"spec": map[string]interface{}{
+				"group":                s.Group,
+				"version":              version,
+				"groupPriorityMinimum": 1000,
+				"versionPriority":      15,
+				"caBundle":             base64.StdEncoding.EncodeToString(s.ca.CertBytes()),
+				"service": map[string]interface{}{
+					"namespace": aggregatedAPIServerNamespace,
+					"name":      s.serviceName(),
+					"port":      port,
+				},
+			},
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// recreate creates the object, deleting an existing one with the same name
+// first.
+func (s *AggregatedAPIServer) recreate(gvr GroupVersionResource, namespace string, object Object) error {
+	_, err := s.client.Create(gvr, namespace, object)
+	if !IsAlreadyExists(err) {
+		return err
+	}
+	if err := s.client.Delete(gvr, namespace, object.Name()); err != nil && !IsNotFound(err) {
+		return err
+	}
+	_, err = s.client.Create(gvr, namespace, object)
+	return err
+}
+
+// waitUntilAvailable waits until the APIServer reports all APIServices as
+// Available, which it does once it reached the AggregatedAPIServer.
+func (s *AggregatedAPIServer) waitUntilAvailable() error {
+	timedOut := time.After(s.StartTimeout)
+	for {
+		available := true
+		for _, version := range s.Versions {
+			apiService, err := s.client.Get(apiServicesResource, "", s.apiServiceName(version))
+			if err != nil {
+				return err
+			}
+			if !hasCondition(apiService, "Available", "True") {
+				available = false
+			}
+		}
+		if available {
+			return nil
+		}
+
+		select {
+		case <-timedOut:
+			return fmt.Errorf("timeout waiting for the APIServices of %s to become available", s.Group)
+		case <-time.After(100 * time.Millisecond):
+		}
+	}
+}
+
+// serviceName returns the name of the Service the APIServices refer to,
+// derived from the Group, e.g. "integration-wardle-example-com".
+func (s *AggregatedAPIServer) serviceName() string {
+	name := "integration-" + strings.Replace(s.Group, ".", "-", -1)
+	if len(name) > 63 {
+		name = name[:63]
+	}
+	return strings.TrimRight(name, "-")
+}
+
+func (s *AggregatedAPIServer) apiServiceName(version string) string {
+	return version + "." + s.Group
+}
+
+// keys returns the keys the APIServices, and the Service they refer to, are
+// stored under in Etcd.
+func (s *AggregatedAPIServer) keys() []string {
+	keys := []string{etcdRegistryPrefix + "services/specs/" + aggregatedAPIServerNamespace + "/" + s.serviceName()}
+	for _, version := range s.Versions {
+		keys = append(keys, etcdRegistryPrefix+"apiregistration.k8s.io/apiservices/"+s.apiServiceName(version))
+	}
+	return keys
+}
@@ -0,0 +1,179 @@
+package integration
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/ghttp"
+
+	"github.com/kubernetes-sigs/testing_frameworks/integration/internal"
+)
+
+var _ = Describe("AggregatedAPIServer", func() {
+	It("needs a started ControlPlane", func() {
+		err := (&ControlPlane{}).StartAggregatedAPIServer(&AggregatedAPIServer{
+			Name: "wardle-apiserver", Group: "wardle.example.com", Versions: []string{"v1alpha1"},
+		})
+		Expect(err).To(MatchError(ContainSubstring("needs to be started")))
+	})
+
+	It("can be stopped without having been started", func() {
+		Expect((&AggregatedAPIServer{}).Stop()).To(Succeed())
+	})
+
+	It("names its Service after the Group", func() {
+		wardle := &AggregatedAPIServer{Group: "wardle.example.com", Versions: []string{"v1alpha1", "v1beta1"}}
+		Expect(wardle.serviceName()).To(Equal("integration-wardle-example-com"))
+		Expect(wardle.keys()).To(Equal([]string{
+			"/registry/services/specs/kube-system/integration-wardle-example-com",
+			"/registry/apiregistration.k8s.io/apiservices/v1alpha1.wardle.example.com",
+			"/registry/apiregistration.k8s.io/apiservices/v1beta1.wardle.example.com",
+		}))
+
+		long := &AggregatedAPIServer{Group: strings.Repeat("a", 51) + ".example.com"}
+		Expect(long.serviceName()).To(Equal("integration-" + strings.Repeat("a", 51)))
+	})
+
+	It("is served with a certificate for its Service, and trusts the APIServer's CAs", func() {
+		apiServer := &APIServer{}
+		var err error
+		apiServer.CertDir, err = ioutil.TempDir("", "aggregated_apiserver_test_")
+		Expect(err).NotTo(HaveOccurred())
+		defer os.RemoveAll(apiServer.CertDir)
+		apiServer.ca, err = internal.NewTinyCA("apiserver-ca")
+		Expect(err).NotTo(HaveOccurred())
+		apiServer.frontProxyCA, err = internal.NewTinyCA("front-proxy-ca")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(internal.WriteFrontProxyCerts(apiServer.CertDir, apiServer.frontProxyCA)).To(Succeed())
+
+		s := &AggregatedAPIServer{
+			Group:     "wardle.example.com",
+			SecureURL: &url.URL{Scheme: "https", Host: "127.0.0.1:8443"},
+			CertDir:   apiServer.CertDir,
+		}
+		Expect(s.writeCerts(apiServer)).To(Succeed())
+
+		certPEM, err := ioutil.ReadFile(s.ServingCertFile())
+		Expect(err).NotTo(HaveOccurred())
+		block, _ := pem.Decode(certPEM)
+		cert, err := x509.ParseCertificate(block.Bytes)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(cert.DNSNames).To(ContainElement("integration-wardle-example-com.kube-system.svc"))
+		Expect(cert.IPAddresses[0].String()).To(Equal("127.0.0.1"))
+
+		Expect(ioutil.ReadFile(s.RequestHeaderCAFile())).To(Equal(apiServer.frontProxyCA.CertBytes()))
+		Expect(ioutil.ReadFile(s.ClientCAFile())).To(Equal(apiServer.ca.CertBytes()))
+	})
+
+	Context("with an APIServer", func() {
+		var (
+			server *recordingServer
+			s      *AggregatedAPIServer
+		)
+
+		BeforeEach(func() {
+			server = newRecordingServer()
+			ca, err := internal.NewTinyCA("aggregated-apiserver-ca")
+			Expect(err).NotTo(HaveOccurred())
+			s = &AggregatedAPIServer{
+				Group:        "wardle.example.com",
+				Versions:     []string{"v1alpha1"},
+				SecureURL:    &url.URL{Scheme: "https", Host: "127.0.0.1:8443"},
+				StartTimeout: time.Second,
+				client:       server.client,
+				ca:           ca,
+			}
+		})
+		AfterEach(func() {
+			server.Close()
+		})
+
+		It("registers an APIService for each version, backed by an ExternalName Service", func() {
+			server.RouteToHandler("POST", "/api/v1/namespaces/kube-system/services", server.record(http.StatusCreated, `{}`))
+			server.RouteToHandler("POST", "/apis/apiregistration.k8s.io/v1/apiservices", server.record(http.StatusCreated, `{}`))
+
+			Expect(s.register()).To(Succeed())
+			Expect(server.recorded()).To(Equal([]string{
+				"POST /api/v1/namespaces/kube-system/services",
+				"POST /apis/apiregistration.k8s.io/v1/apiservices",
+			}))
+
+			service := server.recordedBody("POST /api/v1/namespaces/kube-system/services")
+			Expect(service).To(HaveField("metadata.name", "integration-wardle-example-com"))
+			Expect(service).To(HaveField("spec.type", "ExternalName"))
+			Expect(service).To(HaveField("spec.externalName", "127.0.0.1"))
+
+			apiService := server.recordedBody("POST /apis/apiregistration.k8s.io/v1/apiservices")
+			Expect(apiService).To(HaveField("metadata.name", "v1alpha1.wardle.example.com"))
+			Expect(apiService).To(HaveField("spec.group", "wardle.example.com"))
+			Expect(apiService).To(HaveField("spec.service.name", "integration-wardle-example-com"))
+			Expect(apiService).To(HaveField("spec.service.port", 8443))
+			caBundle, _ := apiService.Field("spec.caBundle")
+			Expect(caBundle).NotTo(BeEmpty())
+		})
+
+		It("replaces the APIServices of an earlier run", func() {
+			server.RouteToHandler("POST", "/api/v1/namespaces/kube-system/services", server.record(http.StatusCreated, `{}`))
+			alreadyExists := true
+			server.RouteToHandler("POST", "/apis/apiregistration.k8s.io/v1/apiservices", func(rw http.ResponseWriter, req *http.Request) {
+				if alreadyExists {
+					alreadyExists = false
+					server.record(http.StatusConflict, `{"kind": "Status", "reason": "AlreadyExists"}`)(rw, req)
+					return
+				}
+				server.record(http.StatusCreated, `{}`)(rw, req)
+			})
+			server.RouteToHandler("DELETE", "/apis/apiregistration.k8s.io/v1/apiservices/v1alpha1.wardle.example.com", server.record(http.StatusOK, `{}`))
+
+			Expect(s.register()).To(Succeed())
+			Expect(server.recorded()).To(Equal([]string{
+				"POST /api/v1/namespaces/kube-system/services",
+				"POST /apis/apiregistration.k8s.io/v1/apiservices",
+				"DELETE /apis/apiregistration.k8s.io/v1/apiservices/v1alpha1.wardle.example.com",
+				"POST /apis/apiregistration.k8s.io/v1/apiservices",
+			}))
+		})
+
+		It("waits until the APIServices are Available", func() {
+			polls := 0
+			server.RouteToHandler("GET", "/apis/apiregistration.k8s.io/v1/apiservices/v1alpha1.wardle.example.com", func(rw http.ResponseWriter, req *http.Request) {
+				polls++
+				status := "False"
+				if polls == 3 {
+					status = "True"
+				}
+				rw.Write([]byte(`{"status": {"conditions": [{"type": "Available", "status": "` + status + `"}]}}`))
+			})
+
+			Expect(s.waitUntilAvailable()).To(Succeed())
+			Expect(polls).To(Equal(3))
+		})
+
+		It("times out if the APIServices do not become Available", func() {
+			s.StartTimeout = 200 * time.Millisecond
+			server.RouteToHandler("GET", "/apis/apiregistration.k8s.io/v1/apiservices/v1alpha1.wardle.example.com",
+				ghttp.RespondWith(http.StatusOK, `{"status": {"conditions": [{"type": "Available", "status": "False"}]}}`))
+
+			Expect(s.waitUntilAvailable()).To(MatchError(ContainSubstring("timeout waiting for the APIServices of wardle.example.com")))
+		})
+
+		It("deregisters itself when stopped", func() {
+			server.RouteToHandler("DELETE", "/apis/apiregistration.k8s.io/v1/apiservices/v1alpha1.wardle.example.com", server.record(http.StatusOK, `{}`))
+			server.RouteToHandler("DELETE", "/api/v1/namespaces/kube-system/services/integration-wardle-example-com", server.record(http.StatusOK, `{}`))
+
+			Expect(s.Stop()).To(Succeed())
+			Expect(server.recorded()).To(Equal([]string{
+				"DELETE /apis/apiregistration.k8s.io/v1/apiservices/v1alpha1.wardle.example.com",
+				"DELETE /api/v1/namespaces/kube-system/services/integration-wardle-example-com",
+			}))
+		})
+	})
+})
@@ -1,9 +1,14 @@
 package integration
 
 import (
+	"crypto/rsa"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"net/url"
+	"path/filepath"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/kubernetes-sigs/testing_frameworks/integration/internal"
@@ -16,6 +21,13 @@ type APIServer struct {
 	// If this is not specified, we default to a random free port on localhost.
 	URL *url.URL
 
+	// SecureURL is the address the APIServer should listen on for TLS
+	// connections. Clients connecting to this address are authenticated, e.g.
+	// by the client certificates handed out for a User.
+	//
+	// If this is not specified, we default to a random free port on localhost.
+	SecureURL *url.URL
+
 	// Path is the path to the apiserver binary.
 	//
 	// If this is left as the empty string, we will attempt to locate a binary,
@@ -43,6 +55,43 @@ type APIServer struct {
 	// directory, and the Stop() method will clean it up.
 	CertDir string
 
+	// AuthorizationMode is the value passed to the APIServer's
+	// --authorization-mode flag, e.g. "RBAC" or "Node,RBAC".
+	//
+	// Requests to the insecure URL are never authorized, so the KubeCtl handed
+	// out by ControlPlane.KubeCtl() always has full access.
+	//
+	// If not specified, this defaults to "Webhook" if an AuthWebhook with an
+	// Authorize function is configured, and to "AlwaysAllow" otherwise.
+	AuthorizationMode string
+
+	// AuthWebhook, if configured, is started before the APIServer and the
+	// APIServer is configured to use it for token authentication and/or
+	// authorization. See the AuthWebhook type for details.
+	AuthWebhook *AuthWebhook
+
+	// Audit, if configured, enables the APIServer's audit log. See the Audit
+	// type for details.
+	Audit *Audit
+
+	// EncryptionConfig, if configured, makes the APIServer encrypt resources
+	// before storing them in Etcd. See the EncryptionConfig type for details.
+	EncryptionConfig *EncryptionConfig
+
+	// OIDCIssuer, if configured, is started before the APIServer and the
+	// APIServer is configured to authenticate the ID tokens it issues. See the
+	// OIDCIssuer type for details.
+	OIDCIssuer *OIDCIssuer
+
+	// ServiceAccountIssuer is the issuer of the ServiceAccount tokens the
+	// APIServer signs, and the audience they are valid for.
+	//
+	// The keys to sign and verify those tokens are generated into the CertDir
+	// when the APIServer starts.
+	//
+	// If not specified, this defaults to "https://kubernetes.default.svc".
+	ServiceAccountIssuer string
+
 	// EtcdURL is the URL of the Etcd the APIServer should use.
 	//
 	// If this is not specified, the Start() method will return an error.
@@ -62,6 +111,18 @@ type APIServer struct {
 	Err io.Writer
 
 	processState *internal.ProcessState
+	ca           *internal.TinyCA
+	frontProxyCA *internal.TinyCA
+	saKey        *rsa.PrivateKey
+
+	clientCertsLock sync.Mutex
+	clientCerts     map[string]clientCertFiles
+}
+
+// clientCertFiles are the paths to a client certificate and its key, written
+// by issueClientCert.
+type clientCertFiles struct {
+	certFile, keyFile string
 }
 
 // Start starts the apiserver, waits for it to come up, and returns an error,
@@ -95,6 +156,84 @@ func (s *APIServer) Start() error {
 	s.StartTimeout = s.processState.StartTimeout
 	s.StopTimeout = s.processState.StopTimeout
 
+	if s.SecureURL == nil {
+		s.SecureURL, err = internal.NewLocalURL("https")
+		if err != nil {
+			return err
+		}
+	}
+
+	if s.AuthorizationMode == "" {
+		s.AuthorizationMode = "AlwaysAllow"
+		if s.AuthWebhook != nil && s.AuthWebhook.Authorize != nil {
+			s.AuthorizationMode = "Webhook"
+		}
+	}
+
+	// The CertDir might have been removed since the last start, and with it
+	// the client certificates issued so far.
+	s.clientCertsLock.Lock()
+	s.clientCerts = nil
+	s.clientCertsLock.Unlock()
+
+	if s.ca == nil {
+		s.ca, err = internal.NewTinyCA("integration-apiserver-ca")
+		if err != nil {
+			return err
+		}
+	}
+	if err := internal.WriteAPIServerCerts(s.CertDir, s.ca, s.SecureURL.Hostname()); err != nil {
+		return err
+	}
+
+	if s.frontProxyCA == nil {
+		s.frontProxyCA, err = internal.NewTinyCA("integration-front-proxy-ca")
+		if err != nil {
+			return err
+		}
+	}
+	if err := internal.WriteFrontProxyCerts(s.CertDir, s.frontProxyCA); err != nil {
+		return err
+	}
+
+	if s.ServiceAccountIssuer == "" {
+		s.ServiceAccountIssuer = "https://kubernetes.default.svc"
+	}
+
+	if s.saKey == nil {
+		s.saKey, err = internal.NewRSAKey()
+		if err != nil {
+			return err
+		}
+	}
+	if err := internal.WriteServiceAccountKeys(s.CertDir, s.saKey); err != nil {
+		return err
+	}
+
+	if s.AuthWebhook != nil {
+		if err := s.AuthWebhook.start(s.CertDir); err != nil {
+			return err
+		}
+	}
+
+	if s.OIDCIssuer != nil {
+		if err := s.OIDCIssuer.start(s.CertDir); err != nil {
+			return err
+		}
+	}
+
+	if s.Audit != nil {
+		if err := s.Audit.writePolicy(s.CertDir); err != nil {
+			return err
+		}
+	}
+
+	if s.EncryptionConfig != nil {
+		if err := s.EncryptionConfig.write(s.CertDir); err != nil {
+			return err
+		}
+	}
+
 	s.processState.Args, err = internal.RenderTemplates(
 		internal.DoAPIServerArgDefaulting(s.Args), s,
 	)
@@ -105,8 +244,95 @@ func (s *APIServer) Start() error {
 	return s.processState.Start(s.Out, s.Err)
 }
 
+// CACertFile returns the path to the certificate of the CA which signed the
+// APIServer's serving certificate, and which the APIServer trusts to sign
+// client certificates.
+func (s *APIServer) CACertFile() string {
+	return filepath.Join(s.CertDir, internal.APIServerCACertFile)
+}
+
+// FrontProxyCACertFile returns the path to the certificate of the CA which
+// signed the client certificate the APIServer authenticates with when
+// proxying requests to aggregated APIServers. They need to trust it, e.g.
+// with "--requestheader-client-ca-file", to accept the user a proxied request
+// is made for.
+func (s *APIServer) FrontProxyCACertFile() string {
+	return filepath.Join(s.CertDir, internal.APIServerFrontProxyCACertFile)
+}
+
+// issueClientCert creates a client certificate for the given user and writes
+// it, alongside its key, into a fresh directory in the CertDir. The files are
+// reused for later calls with the same user name and groups.
+func (s *APIServer) issueClientCert(user User) (certFile, keyFile string, err error) {
+	s.clientCertsLock.Lock()
+	defer s.clientCertsLock.Unlock()
+
+	userKey := strings.Join(append([]string{user.Name}, user.Groups...), "\n")
+	if files, ok := s.clientCerts[userKey]; ok {
+		return files.certFile, files.keyFile, nil
+	}
+
+	cert, key, err := s.clientCert(user)
+	if err != nil {
+		return "", "", err
+	}
+
+	dir, err := ioutil.TempDir(s.CertDir, "user-")
+	if err != nil {
+		return "", "", err
+	}
+	certFile = filepath.Join(dir, "client.crt")
+	keyFile = filepath.Join(dir, "client.key")
+	if err := ioutil.WriteFile(certFile, cert, 0600); err != nil {
+		return "", "", err
+	}
+	if err := ioutil.WriteFile(keyFile, key, 0600); err != nil {
+		return "", "", err
+	}
+
+	if s.clientCerts == nil {
+		s.clientCerts = map[string]clientCertFiles{}
+	}
+	s.clientCerts[userKey] = clientCertFiles{certFile: certFile, keyFile: keyFile}
+	return certFile, keyFile, nil
+}
+
+// clientCert creates a client certificate for the given user and returns it,
+// alongside its key, PEM encoded.
+func (s *APIServer) clientCert(user User) (cert, key []byte, err error) {
+	if s.ca == nil {
+		return nil, nil, fmt.Errorf("the APIServer needs to be started before issuing client certificates")
+	}
+
+	certPair, err := s.ca.NewClientCert(user.Name, user.Groups...)
+	if err != nil {
+		return nil, nil, err
+	}
+	return certPair.AsBytes()
+}
+
+// restart stops the APIServer, calls whileStopped, and starts it again with
+// the same configuration, URLs and CertDir.
+func (s *APIServer) restart(whileStopped func() error) error {
+	if s.processState == nil {
+		return fmt.Errorf("the APIServer needs to be started before it can be restarted")
+	}
+	return s.processState.Restart(s.Out, s.Err, whileStopped)
+}
+
 // Stop stops this process gracefully, waits for its termination, and cleans up
 // the CertDir if necessary.
 func (s *APIServer) Stop() error {
-	return s.processState.Stop()
+	if err := s.processState.Stop(); err != nil {
+		return err
+	}
+	if s.AuthWebhook != nil {
+		if err := s.AuthWebhook.stop(); err != nil {
+			return err
+		}
+	}
+	if s.OIDCIssuer != nil {
+		return s.OIDCIssuer.stop()
+	}
+	return nil
 }
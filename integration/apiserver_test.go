@@ -0,0 +1,48 @@
+package integration
+
+import (
+	"io/ioutil"
+	"os"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/kubernetes-sigs/testing_frameworks/integration/internal"
+)
+
+var _ = Describe("APIServer", func() {
+	var apiServer *APIServer
+
+	BeforeEach(func() {
+		certDir, err := ioutil.TempDir("", "k8s_test_framework_")
+		Expect(err).NotTo(HaveOccurred())
+		ca, err := internal.NewTinyCA("apiserver-ca")
+		Expect(err).NotTo(HaveOccurred())
+		apiServer = &APIServer{CertDir: certDir, ca: ca}
+	})
+	AfterEach(func() {
+		Expect(os.RemoveAll(apiServer.CertDir)).To(Succeed())
+	})
+
+	It("issues one client certificate per user name and groups", func() {
+		jane := User{Name: "jane", Groups: []string{"developers"}}
+
+		certFile, keyFile, err := apiServer.issueClientCert(jane)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(certFile).To(BeARegularFile())
+		Expect(keyFile).To(BeARegularFile())
+
+		sameCertFile, sameKeyFile, err := apiServer.issueClientCert(User{Name: "jane", Groups: []string{"developers"}})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(sameCertFile).To(Equal(certFile))
+		Expect(sameKeyFile).To(Equal(keyFile))
+
+		otherCertFile, _, err := apiServer.issueClientCert(User{Name: "jane", Groups: []string{"admins"}})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(otherCertFile).NotTo(Equal(certFile))
+
+		otherCertFile, _, err = apiServer.issueClientCert(User{Name: "john", Groups: []string{"developers"}})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(otherCertFile).NotTo(Equal(certFile))
+	})
+})
@@ -0,0 +1,343 @@
+package integration
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/onsi/gomega/format"
+	"github.com/onsi/gomega/types"
+	yaml "gopkg.in/yaml.v2"
+)
+
+// Audit configures the audit log of an APIServer. If configured, the
+// APIServer writes an audit event for every request it handles into a log
+// file in its CertDir, which can be inspected with APIServer.AuditEvents().
+//
+// For example, to log all requests on deployments with their request and
+// response bodies, and all other requests with their metadata only:
+//
+//	apiServer := &integration.APIServer{
+//		Audit: &integration.Audit{
+//			Rules: []integration.AuditRule{{
+//				Level:     "RequestResponse",
+//				Group:     "apps",
+//				Resources: []string{"deployments"},
+//			}},
+//		},
+//	}
+type Audit struct {
+	// Rules define the level requests on specific resources are logged with.
+	// The first rule matching a request decides its level.
+	Rules []AuditRule
+
+	// DefaultLevel is the level requests not matching any rule are logged
+	// with. One of "None", "Metadata", "Request" or "RequestResponse".
+	//
+	// If not specified, this defaults to "Metadata".
+	DefaultLevel string
+
+	policyFile string
+	logFile    string
+}
+
+// AuditRule defines the level requests on resources are logged with.
+type AuditRule struct {
+	// Level is one of "None", "Metadata", "Request" or "RequestResponse".
+	Level string
+
+	// Group is the API group of the resources, the empty string for the core
+	// group.
+	Group string
+
+	// Resources are the resources this rule applies to, e.g. "deployments"
+	// or "pods/log". If empty, the rule applies to all resources in the Group.
+	Resources []string
+
+	// Verbs are the verbs this rule applies to, e.g. "create". If empty, the
+	// rule applies to all verbs.
+	Verbs []string
+}
+
+// PolicyFile returns the path to the generated audit policy. It is empty if
+// the APIServer has not been started yet.
+func (a *Audit) PolicyFile() string {
+	return a.policyFile
+}
+
+// LogFile returns the path to the audit log. It is empty if the APIServer has
+// not been started yet.
+func (a *Audit) LogFile() string {
+	return a.logFile
+}
+
+type auditPolicy struct {
+	APIVersion string            `yaml:"apiVersion"`
+	Kind       string            `yaml:"kind"`
+	OmitStages []string          `yaml:"omitStages"`
+	Rules      []auditPolicyRule `yaml:"rules"`
+}
+
+type auditPolicyRule struct {
+	Level     string                 `yaml:"level"`
+	Verbs     []string               `yaml:"verbs,omitempty"`
+	Resources []auditPolicyResources `yaml:"resources,omitempty"`
+}
+
+type auditPolicyResources struct {
+	Group     string   `yaml:"group"`
+	Resources []string `yaml:"resources,omitempty"`
+}
+
+// writePolicy writes the audit policy into dir and decides where the audit
+// log is written to.
+func (a *Audit) writePolicy(dir string) error {
+	if a.DefaultLevel == "" {
+		a.DefaultLevel = "Metadata"
+	}
+
+	policy := auditPolicy{
+		APIVersion: "audit.k8s.io/v1beta1",
+		Kind:       "Policy",
+		// Only log one event per request, after the response has been sent.
+		OmitStages: []string{"RequestReceived"},
+	}
+	for _, rule := range a.Rules {
+		policy.Rules = append(policy.Rules, auditPolicyRule{
+			Level: rule.Level,
+			Verbs: rule.Verbs,
+			Resources: []auditPolicyResources{{
+				Group:     rule.Group,
+				Resources: rule.Resources,
+			}},
+		})
+	}
+	policy.Rules = append(policy.Rules, auditPolicyRule{Level: a.DefaultLevel})
+
+	content, err := yaml.Marshal(policy)
+	if err != nil {
+		return err
+	}
+
+	a.policyFile = filepath.Join(dir, "audit-policy.yaml")
+	a.logFile = filepath.Join(dir, "audit.log")
+	return ioutil.WriteFile(a.policyFile, content, 0600)
+}
+
+// AuditEvent is an entry of the APIServer's audit log.
+type AuditEvent struct {
+	Level      string `json:"level"`
+	AuditID    string `json:"auditID"`
+	Stage      string `json:"stage"`
+	RequestURI string `json:"requestURI"`
+	Verb       string `json:"verb"`
+
+	User             UserInfo  `json:"user"`
+	ImpersonatedUser *UserInfo `json:"impersonatedUser,omitempty"`
+	SourceIPs        []string  `json:"sourceIPs,omitempty"`
+	UserAgent        string    `json:"userAgent,omitempty"`
+
+	ObjectRef      *AuditObjectRef      `json:"objectRef,omitempty"`
+	ResponseStatus *AuditResponseStatus `json:"responseStatus,omitempty"`
+
+	// RequestObject and ResponseObject are only logged on the "Request" and
+	// "RequestResponse" levels.
+	RequestObject  json.RawMessage `json:"requestObject,omitempty"`
+	ResponseObject json.RawMessage `json:"responseObject,omitempty"`
+
+	RequestReceivedTimestamp time.Time `json:"requestReceivedTimestamp"`
+	StageTimestamp           time.Time `json:"stageTimestamp"`
+}
+
+// AuditObjectRef references the object a request was made for.
+type AuditObjectRef struct {
+	Resource        string `json:"resource,omitempty"`
+	Namespace       string `json:"namespace,omitempty"`
+	Name            string `json:"name,omitempty"`
+	UID             string `json:"uid,omitempty"`
+	APIGroup        string `json:"apiGroup,omitempty"`
+	APIVersion      string `json:"apiVersion,omitempty"`
+	ResourceVersion string `json:"resourceVersion,omitempty"`
+	Subresource     string `json:"subresource,omitempty"`
+}
+
+// AuditResponseStatus is the status the APIServer responded with.
+type AuditResponseStatus struct {
+	Code    int    `json:"code,omitempty"`
+	Status  string `json:"status,omitempty"`
+	Reason  string `json:"reason,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+// AuditEvents reads and parses all the events the APIServer has written to
+// its audit log so far, in the order they have been written. It returns an
+// error if the APIServer is not configured for auditing.
+//
+// It can be polled to wait for an event, e.g.:
+//
+//	Eventually(apiServer.AuditEvents).Should(HaveAuditEvent(AuditEventFilter{
+//		Verb:     "create",
+//		Resource: "deployments",
+//		User:     "jane",
+//	}))
+func (s *APIServer) AuditEvents() ([]AuditEvent, error) {
+	if s.Audit == nil || s.Audit.LogFile() == "" {
+		return nil, fmt.Errorf("the APIServer is not configured for auditing")
+	}
+
+	f, err := os.Open(s.Audit.LogFile())
+	if os.IsNotExist(err) {
+		return []AuditEvent{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	events := []AuditEvent{}
+	reader := bufio.NewReader(f)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if len(line) > 0 && line[len(line)-1] == '\n' {
+			event := AuditEvent{}
+			if err := json.Unmarshal(line, &event); err != nil {
+				return nil, err
+			}
+			events = append(events, event)
+		}
+		// A line without a trailing newline is still being written by the
+		// APIServer, so we ignore it for now.
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return events, nil
+}
+
+// AuditEventFilter selects audit events. Empty fields match any value.
+type AuditEventFilter struct {
+	Verb        string
+	User        string
+	Group       string
+	APIGroup    string
+	Resource    string
+	Subresource string
+	Namespace   string
+	Name        string
+	Code        int
+}
+
+// Matches returns true if the event matches all non-empty fields of the
+// filter. User matches the username and Group any of the groups of the
+// requesting user.
+func (f AuditEventFilter) Matches(event AuditEvent) bool {
+	if f.Verb != "" && f.Verb != event.Verb {
+		return false
+	}
+	if f.User != "" && f.User != event.User.Username {
+		return false
+	}
+	if f.Group != "" && !containsString(event.User.Groups, f.Group) {
+		return false
+	}
+	if f.Code != 0 && (event.ResponseStatus == nil || f.Code != event.ResponseStatus.Code) {
+		return false
+	}
+
+	ref := event.ObjectRef
+	if ref == nil {
+		ref = &AuditObjectRef{}
+	}
+	if f.APIGroup != "" && f.APIGroup != ref.APIGroup {
+		return false
+	}
+	if f.Resource != "" && f.Resource != ref.Resource {
+		return false
+	}
+	if f.Subresource != "" && f.Subresource != ref.Subresource {
+		return false
+	}
+	if f.Namespace != "" && f.Namespace != ref.Namespace {
+		return false
+	}
+	if f.Name != "" && f.Name != ref.Name {
+		return false
+	}
+
+	return true
+}
+
+// FilterAuditEvents returns the events matching the filter.
+func FilterAuditEvents(events []AuditEvent, filter AuditEventFilter) []AuditEvent {
+	matching := []AuditEvent{}
+	for _, event := range events {
+		if filter.Matches(event) {
+			matching = append(matching, event)
+		}
+	}
+	return matching
+}
+
+// HaveAuditEvent succeeds if actual, a []AuditEvent, contains at least one
+// event matching the filter.
+func HaveAuditEvent(filter AuditEventFilter) types.GomegaMatcher {
+	return &auditEventMatcher{filter: filter}
+}
+
+type auditEventMatcher struct {
+	filter AuditEventFilter
+}
+
+func (m *auditEventMatcher) Match(actual interface{}) (bool, error) {
+	events, ok := actual.([]AuditEvent)
+	if !ok {
+		return false, fmt.Errorf("HaveAuditEvent matcher expects a []AuditEvent. Got:\n%s", format.Object(actual, 1))
+	}
+	return len(FilterAuditEvents(events, m.filter)) > 0, nil
+}
+
+func (m *auditEventMatcher) FailureMessage(actual interface{}) string {
+	return format.Message(summarizeAuditEvents(actual), "to contain an audit event matching", m.filter)
+}
+
+func (m *auditEventMatcher) NegatedFailureMessage(actual interface{}) string {
+	return format.Message(summarizeAuditEvents(actual), "not to contain an audit event matching", m.filter)
+}
+
+// summarizeAuditEvents renders one line per event, as the full events are
+// too verbose to be useful in a failure message.
+func summarizeAuditEvents(actual interface{}) interface{} {
+	events, ok := actual.([]AuditEvent)
+	if !ok {
+		return actual
+	}
+
+	lines := []string{}
+	for _, e := range events {
+		code := 0
+		if e.ResponseStatus != nil {
+			code = e.ResponseStatus.Code
+		}
+		lines = append(lines, fmt.Sprintf("%s %s by %s: %d", e.Verb, e.RequestURI, e.User.Username, code))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func containsString(haystack []string, needle string) bool {
+	for _, s := range haystack {
+		if s == needle {
+			return true
+		}
+	}
+	return false
+}
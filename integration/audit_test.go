@@ -0,0 +1,87 @@
+package integration
+
+import (
+	"io/ioutil"
+	"os"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	yaml "gopkg.in/yaml.v2"
+)
+
+var _ = Describe("Audit", func() {
+	var (
+		apiServer *APIServer
+		dir       string
+	)
+
+	BeforeEach(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "k8s_test_framework_")
+		Expect(err).NotTo(HaveOccurred())
+
+		apiServer = &APIServer{
+			Audit: &Audit{
+				Rules: []AuditRule{{
+					Level:     "RequestResponse",
+					Group:     "apps",
+					Resources: []string{"deployments"},
+				}},
+			},
+		}
+		Expect(apiServer.Audit.writePolicy(dir)).To(Succeed())
+	})
+	AfterEach(func() {
+		Expect(os.RemoveAll(dir)).To(Succeed())
+	})
+
+	It("generates a policy with the rules followed by the default level", func() {
+		content, err := ioutil.ReadFile(apiServer.Audit.PolicyFile())
+		Expect(err).NotTo(HaveOccurred())
+
+		policy := auditPolicy{}
+		Expect(yaml.Unmarshal(content, &policy)).To(Succeed())
+		Expect(policy.Kind).To(Equal("Policy"))
+		Expect(policy.Rules).To(Equal([]auditPolicyRule{
+			{
+				Level:     "RequestResponse",
+				Resources: []auditPolicyResources{{Group: "apps", Resources: []string{"deployments"}}},
+			},
+			{Level: "Metadata"},
+		}))
+	})
+
+	Describe("AuditEvents", func() {
+		It("returns no events when nothing has been logged yet", func() {
+			Expect(apiServer.AuditEvents()).To(BeEmpty())
+		})
+
+		It("parses the complete events of the log", func() {
+			log := `{"level":"Metadata","verb":"create","user":{"username":"jane","groups":["devs"]},"objectRef":{"resource":"deployments","namespace":"default","name":"web","apiGroup":"apps"},"responseStatus":{"code":201},"stageTimestamp":"2018-05-04T10:11:12.123456Z"}
+{"level":"Metadata","verb":"list","user":{"username":"joe"},"objectRef":{"resource":"pods","namespace":"default"},"responseStatus":{"code":403}}
+{"level":"Metadata","verb":"get","user":{"usern`
+			Expect(ioutil.WriteFile(apiServer.Audit.LogFile(), []byte(log), 0600)).To(Succeed())
+
+			events, err := apiServer.AuditEvents()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(events).To(HaveLen(2))
+			Expect(events[0].ObjectRef.Name).To(Equal("web"))
+			Expect(events[0].StageTimestamp.Year()).To(Equal(2018))
+
+			Expect(events).To(HaveAuditEvent(AuditEventFilter{
+				Verb: "create", Resource: "deployments", User: "jane", Code: 201,
+			}))
+			Expect(events).To(HaveAuditEvent(AuditEventFilter{Group: "devs"}))
+			Expect(events).To(HaveAuditEvent(AuditEventFilter{Verb: "list", Code: 403}))
+			Expect(events).NotTo(HaveAuditEvent(AuditEventFilter{Verb: "create", User: "joe"}))
+			Expect(events).NotTo(HaveAuditEvent(AuditEventFilter{Verb: "get"}))
+
+			Expect(FilterAuditEvents(events, AuditEventFilter{Namespace: "default"})).To(HaveLen(2))
+		})
+
+		It("errors if auditing is not configured", func() {
+			_, err := (&APIServer{}).AuditEvents()
+			Expect(err).To(MatchError(ContainSubstring("not configured for auditing")))
+		})
+	})
+})
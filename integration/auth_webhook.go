@@ -0,0 +1,270 @@
+package integration
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"path/filepath"
+	"sync"
+
+	"github.com/kubernetes-sigs/testing_frameworks/integration/internal"
+)
+
+// AuthWebhook is a stand-in for a webhook token authenticator and a webhook
+// authorizer, served from within the test process. The APIServer sends every
+// TokenReview and SubjectAccessReview to it, and the functions configured on
+// the AuthWebhook decide on them.
+//
+// To use it, configure it on the APIServer before starting it:
+//
+//	webhook := &integration.AuthWebhook{
+//		Authenticate: func(spec integration.TokenReviewSpec) integration.TokenReviewStatus {
+//			if spec.Token == "secret" {
+//				return integration.TokenReviewStatus{
+//					Authenticated: true,
+//					User:          integration.UserInfo{Username: "jane"},
+//				}
+//			}
+//			return integration.TokenReviewStatus{}
+//		},
+//		Authorize: func(spec integration.SubjectAccessReviewSpec) integration.SubjectAccessReviewStatus {
+//			return integration.SubjectAccessReviewStatus{Allowed: spec.User == "jane"}
+//		},
+//	}
+//	cp := &integration.ControlPlane{
+//		APIServer: &integration.APIServer{AuthWebhook: webhook},
+//	}
+type AuthWebhook struct {
+	// Authenticate decides on TokenReviews. If it is nil, the APIServer is not
+	// configured to authenticate tokens via this webhook.
+	Authenticate func(TokenReviewSpec) TokenReviewStatus
+
+	// Authorize decides on SubjectAccessReviews. If it is nil, the APIServer is
+	// not configured to authorize requests via this webhook.
+	//
+	// Note that the APIServer only consults this webhook if its
+	// AuthorizationMode contains "Webhook". If the APIServer's
+	// AuthorizationMode is not specified, it defaults to "Webhook" when this is
+	// set.
+	Authorize func(SubjectAccessReviewSpec) SubjectAccessReviewStatus
+
+	mu                   sync.Mutex
+	tokenReviews         []TokenReview
+	subjectAccessReviews []SubjectAccessReview
+
+	server                   *internal.TLSServer
+	authenticationConfigFile string
+	authorizationConfigFile  string
+}
+
+// TokenReview is a request to authenticate a token, alongside the decision
+// made on it.
+type TokenReview struct {
+	APIVersion string            `json:"apiVersion"`
+	Kind       string            `json:"kind"`
+	Spec       TokenReviewSpec   `json:"spec"`
+	Status     TokenReviewStatus `json:"status"`
+}
+
+// TokenReviewSpec holds the token to authenticate.
+type TokenReviewSpec struct {
+	Token     string   `json:"token"`
+	Audiences []string `json:"audiences,omitempty"`
+}
+
+// TokenReviewStatus is the result of authenticating a token.
+type TokenReviewStatus struct {
+	Authenticated bool     `json:"authenticated"`
+	User          UserInfo `json:"user,omitempty"`
+	Audiences     []string `json:"audiences,omitempty"`
+	Error         string   `json:"error,omitempty"`
+}
+
+// UserInfo describes an authenticated user.
+type UserInfo struct {
+	Username string              `json:"username,omitempty"`
+	UID      string              `json:"uid,omitempty"`
+	Groups   []string            `json:"groups,omitempty"`
+	Extra    map[string][]string `json:"extra,omitempty"`
+}
+
+// SubjectAccessReview is a request to authorize a user's action, alongside
+// the decision made on it.
+type SubjectAccessReview struct {
+	APIVersion string                    `json:"apiVersion"`
+	Kind       string                    `json:"kind"`
+	Spec       SubjectAccessReviewSpec   `json:"spec"`
+	Status     SubjectAccessReviewStatus `json:"status"`
+}
+
+// SubjectAccessReviewSpec describes the action to authorize. Exactly one of
+// ResourceAttributes and NonResourceAttributes is set.
+type SubjectAccessReviewSpec struct {
+	ResourceAttributes    *ResourceAttributes    `json:"resourceAttributes,omitempty"`
+	NonResourceAttributes *NonResourceAttributes `json:"nonResourceAttributes,omitempty"`
+
+	User string `json:"user,omitempty"`
+	// Groups holds the groups of the user. The v1beta1 API calls this field
+	// "group", the v1 API calls it "groups"; both are decoded into Groups.
+	Groups []string            `json:"groups,omitempty"`
+	Extra  map[string][]string `json:"extra,omitempty"`
+	UID    string              `json:"uid,omitempty"`
+}
+
+// ResourceAttributes describe an action on a resource.
+type ResourceAttributes struct {
+	Namespace   string `json:"namespace,omitempty"`
+	Verb        string `json:"verb,omitempty"`
+	Group       string `json:"group,omitempty"`
+	Version     string `json:"version,omitempty"`
+	Resource    string `json:"resource,omitempty"`
+	Subresource string `json:"subresource,omitempty"`
+	Name        string `json:"name,omitempty"`
+}
+
+// NonResourceAttributes describe an action on a non-resource path, e.g.
+// "get" on "/healthz".
+type NonResourceAttributes struct {
+	Path string `json:"path,omitempty"`
+	Verb string `json:"verb,omitempty"`
+}
+
+// SubjectAccessReviewStatus is the result of authorizing an action.
+type SubjectAccessReviewStatus struct {
+	Allowed         bool   `json:"allowed"`
+	Denied          bool   `json:"denied,omitempty"`
+	Reason          string `json:"reason,omitempty"`
+	EvaluationError string `json:"evaluationError,omitempty"`
+}
+
+// UnmarshalJSON decodes both the v1beta1 ("group") and the v1 ("groups")
+// representation of the user's groups.
+func (s *SubjectAccessReviewSpec) UnmarshalJSON(data []byte) error {
+	type plain SubjectAccessReviewSpec
+	aux := struct {
+		*plain
+		Group []string `json:"group,omitempty"`
+	}{plain: (*plain)(s)}
+
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	s.Groups = append(s.Groups, aux.Group...)
+	return nil
+}
+
+// TokenReviews returns all the TokenReviews the APIServer sent so far,
+// alongside the decisions made on them.
+func (w *AuthWebhook) TokenReviews() []TokenReview {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return append([]TokenReview{}, w.tokenReviews...)
+}
+
+// SubjectAccessReviews returns all the SubjectAccessReviews the APIServer
+// sent so far, alongside the decisions made on them.
+func (w *AuthWebhook) SubjectAccessReviews() []SubjectAccessReview {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return append([]SubjectAccessReview{}, w.subjectAccessReviews...)
+}
+
+// AuthenticationConfigFile returns the path to the kubeconfig the APIServer
+// uses to call this webhook for authentication. It is empty if Authenticate
+// is not set or the webhook is not started yet.
+func (w *AuthWebhook) AuthenticationConfigFile() string {
+	return w.authenticationConfigFile
+}
+
+// AuthorizationConfigFile returns the path to the kubeconfig the APIServer
+// uses to call this webhook for authorization. It is empty if Authorize is
+// not set or the webhook is not started yet.
+func (w *AuthWebhook) AuthorizationConfigFile() string {
+	return w.authorizationConfigFile
+}
+
+// start starts serving the webhook and writes the kubeconfigs for the
+// APIServer into dir.
+func (w *AuthWebhook) start(dir string) error {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/authenticate", w.serveTokenReview)
+	mux.HandleFunc("/authorize", w.serveSubjectAccessReview)
+
+	var err error
+	w.server, err = internal.StartTLSServer("auth-webhook", mux)
+	if err != nil {
+		return err
+	}
+
+	writeConfig := func(name, path string) (string, error) {
+		file := filepath.Join(dir, name)
+		cluster := internal.Cluster{
+			Server:                   w.server.URL.String() + path,
+			CertificateAuthorityData: base64.StdEncoding.EncodeToString(w.server.CA.CertBytes()),
+		}
+		config := internal.NewKubeConfig("webhook", cluster, internal.AuthInfo{}, "")
+		return file, internal.WriteKubeConfig(file, config)
+	}
+
+	if w.Authenticate != nil {
+		w.authenticationConfigFile, err = writeConfig("authentication-webhook.kubeconfig", "/authenticate")
+		if err != nil {
+			return err
+		}
+	}
+	if w.Authorize != nil {
+		w.authorizationConfigFile, err = writeConfig("authorization-webhook.kubeconfig", "/authorize")
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// stop stops serving the webhook.
+func (w *AuthWebhook) stop() error {
+	if w.server == nil {
+		return nil
+	}
+	return w.server.Close()
+}
+
+func (w *AuthWebhook) serveTokenReview(rw http.ResponseWriter, req *http.Request) {
+	review := TokenReview{}
+	if err := json.NewDecoder(req.Body).Decode(&review); err != nil {
+		http.Error(rw, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	review.Status = w.Authenticate(review.Spec)
+
+	w.mu.Lock()
+	w.tokenReviews = append(w.tokenReviews, review)
+	w.mu.Unlock()
+
+	writeJSON(rw, review)
+}
+
+func (w *AuthWebhook) serveSubjectAccessReview(rw http.ResponseWriter, req *http.Request) {
+	review := SubjectAccessReview{}
+	if err := json.NewDecoder(req.Body).Decode(&review); err != nil {
+		http.Error(rw, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	review.Status = w.Authorize(review.Spec)
+
+	w.mu.Lock()
+	w.subjectAccessReviews = append(w.subjectAccessReviews, review)
+	w.mu.Unlock()
+
+	writeJSON(rw, review)
+}
+
+func writeJSON(rw http.ResponseWriter, body interface{}) {
+	rw.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(rw).Encode(body); err != nil {
+		http.Error(rw, err.Error(), http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,143 @@
+package integration
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"os"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	yaml "gopkg.in/yaml.v2"
+
+	"github.com/kubernetes-sigs/testing_frameworks/integration/internal"
+)
+
+var _ = Describe("AuthWebhook", func() {
+	var (
+		webhook *AuthWebhook
+		dir     string
+	)
+
+	BeforeEach(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "k8s_test_framework_")
+		Expect(err).NotTo(HaveOccurred())
+
+		webhook = &AuthWebhook{
+			Authenticate: func(spec TokenReviewSpec) TokenReviewStatus {
+				if spec.Token == "secret" {
+					return TokenReviewStatus{
+						Authenticated: true,
+						User:          UserInfo{Username: "jane", Groups: []string{"devs"}},
+					}
+				}
+				return TokenReviewStatus{Error: "unknown token"}
+			},
+			Authorize: func(spec SubjectAccessReviewSpec) SubjectAccessReviewStatus {
+				for _, group := range spec.Groups {
+					if group == "devs" {
+						return SubjectAccessReviewStatus{Allowed: true}
+					}
+				}
+				return SubjectAccessReviewStatus{Reason: "not a dev"}
+			},
+		}
+		Expect(webhook.start(dir)).To(Succeed())
+	})
+	AfterEach(func() {
+		Expect(webhook.stop()).To(Succeed())
+		Expect(os.RemoveAll(dir)).To(Succeed())
+	})
+
+	// post sends the review to the webhook, the same way the APIServer would,
+	// by using the generated kubeconfig.
+	post := func(configFile string, review, into interface{}) {
+		content, err := ioutil.ReadFile(configFile)
+		ExpectWithOffset(1, err).NotTo(HaveOccurred())
+		config := internal.KubeConfig{}
+		ExpectWithOffset(1, yaml.Unmarshal(content, &config)).To(Succeed())
+		ExpectWithOffset(1, config.Clusters).To(HaveLen(1))
+		cluster := config.Clusters[0].Cluster
+
+		caData, err := base64.StdEncoding.DecodeString(cluster.CertificateAuthorityData)
+		ExpectWithOffset(1, err).NotTo(HaveOccurred())
+		pool := x509.NewCertPool()
+		ExpectWithOffset(1, pool.AppendCertsFromPEM(caData)).To(BeTrue())
+		client := &http.Client{Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{RootCAs: pool},
+		}}
+
+		body, err := json.Marshal(review)
+		ExpectWithOffset(1, err).NotTo(HaveOccurred())
+		res, err := client.Post(cluster.Server, "application/json", bytes.NewReader(body))
+		ExpectWithOffset(1, err).NotTo(HaveOccurred())
+		defer res.Body.Close()
+		ExpectWithOffset(1, res.StatusCode).To(Equal(http.StatusOK))
+		ExpectWithOffset(1, json.NewDecoder(res.Body).Decode(into)).To(Succeed())
+	}
+
+	It("decides on and records TokenReviews", func() {
+		review := TokenReview{}
+		post(webhook.AuthenticationConfigFile(), map[string]interface{}{
+			"apiVersion": "authentication.k8s.io/v1beta1",
+			"kind":       "TokenReview",
+			"spec":       map[string]interface{}{"token": "secret"},
+		}, &review)
+
+		Expect(review.APIVersion).To(Equal("authentication.k8s.io/v1beta1"))
+		Expect(review.Status.Authenticated).To(BeTrue())
+		Expect(review.Status.User.Username).To(Equal("jane"))
+
+		post(webhook.AuthenticationConfigFile(), map[string]interface{}{
+			"spec": map[string]interface{}{"token": "wrong"},
+		}, &review)
+		Expect(review.Status.Authenticated).To(BeFalse())
+
+		reviews := webhook.TokenReviews()
+		Expect(reviews).To(HaveLen(2))
+		Expect(reviews[0].Spec.Token).To(Equal("secret"))
+		Expect(reviews[1].Status.Error).To(Equal("unknown token"))
+	})
+
+	It("decides on and records SubjectAccessReviews", func() {
+		review := SubjectAccessReview{}
+		post(webhook.AuthorizationConfigFile(), map[string]interface{}{
+			"apiVersion": "authorization.k8s.io/v1beta1",
+			"kind":       "SubjectAccessReview",
+			"spec": map[string]interface{}{
+				"user":  "jane",
+				"group": []string{"devs"},
+				"resourceAttributes": map[string]interface{}{
+					"verb":     "list",
+					"resource": "pods",
+				},
+			},
+		}, &review)
+
+		Expect(review.Status.Allowed).To(BeTrue())
+
+		reviews := webhook.SubjectAccessReviews()
+		Expect(reviews).To(HaveLen(1))
+		Expect(reviews[0].Spec.User).To(Equal("jane"))
+		Expect(reviews[0].Spec.Groups).To(ConsistOf("devs"))
+		Expect(reviews[0].Spec.ResourceAttributes.Resource).To(Equal("pods"))
+	})
+
+	Context("when only some decision functions are configured", func() {
+		It("only writes the kubeconfigs for those", func() {
+			onlyAuthn := &AuthWebhook{
+				Authenticate: func(TokenReviewSpec) TokenReviewStatus { return TokenReviewStatus{} },
+			}
+			Expect(onlyAuthn.start(dir)).To(Succeed())
+			defer onlyAuthn.stop()
+
+			Expect(onlyAuthn.AuthenticationConfigFile()).To(BeAnExistingFile())
+			Expect(onlyAuthn.AuthorizationConfigFile()).To(BeEmpty())
+		})
+	})
+})
@@ -0,0 +1,127 @@
+package integration
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Checkpoint saves the current state of the cluster, i.e. the data of its
+// Etcd, under the name, replacing an earlier checkpoint with the same name.
+// Rollback restores it. This allows to build an expensive baseline once, and
+// to return to it before each spec:
+//
+//	BeforeSuite(func() {
+//		Expect(cp.Start()).To(Succeed())
+//		_, err := cp.ApplyManifests("testdata/baseline")
+//		Expect(err).NotTo(HaveOccurred())
+//		Expect(cp.Checkpoint("baseline")).To(Succeed())
+//	})
+//	BeforeEach(func() { Expect(cp.Rollback("baseline")).To(Succeed()) })
+//
+// The Etcd is restarted to save the checkpoint, which the APIServer recovers
+// from before Checkpoint returns. Checkpoints are removed when the
+// ControlPlane stops.
+func (f *ControlPlane) Checkpoint(name string) error {
+	dir, err := f.checkpointDir(name)
+	if err != nil {
+		return err
+	}
+	if err := os.RemoveAll(dir); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(dir), 0700); err != nil {
+		return err
+	}
+
+	if err := f.Etcd.SaveSnapshot(dir); err != nil {
+		return err
+	}
+	return f.waitForAPIServer()
+}
+
+// Rollback restores the state of the cluster saved by Checkpoint under the
+// name. The Etcd, the APIServer, and the ControllerManager, Scheduler,
+// Controllers and Processes, if any, are restarted, on the same URLs, so their
+// caches start over from the restored state.
+//
+// Watchers started by Watch are stopped, as the changes they received are
+// undone. FakeNodes are stopped, too: Nodes saved in the checkpoint are
+// restored, but without heartbeats. The kubeconfig, KubeCtls and RESTClients
+// keep working.
+func (f *ControlPlane) Rollback(name string) error {
+	dir, err := f.checkpointDir(name)
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(dir); err != nil {
+		return fmt.Errorf("there is no checkpoint named %q", name)
+	}
+
+	f.stopWatchers()
+	f.stopFakeNodes()
+	if f.Controllers != nil {
+		f.Controllers.stopAndWait()
+		defer f.Controllers.start()
+	}
+	restore := func() error {
+		return f.APIServer.restart(func() error {
+			return f.Etcd.RestoreSnapshot(dir)
+		})
+	}
+	if f.ControllerManager != nil {
+		restoreWithAPIServer := restore
+		restore = func() error {
+			return f.ControllerManager.restart(restoreWithAPIServer)
+		}
+	}
+	if f.Scheduler != nil {
+		restoreWithControllers := restore
+		restore = func() error {
+			return f.Scheduler.restart(restoreWithControllers)
+		}
+	}
+	for _, process := range f.Processes {
+		restoreWithOthers, process := restore, process
+		restore = func() error {
+			return process.restart(restoreWithOthers)
+		}
+	}
+	return restore()
+}
+
+func (f *ControlPlane) checkpointDir(name string) (string, error) {
+	if f.dir == "" || f.Etcd == nil || !f.Etcd.running() {
+		return "", fmt.Errorf("the ControlPlane needs to be started to use checkpoints")
+	}
+	if name == "" || filepath.Base(name) != name {
+		return "", fmt.Errorf("invalid checkpoint name %q", name)
+	}
+	return filepath.Join(f.dir, "checkpoints", name), nil
+}
+
+// waitForAPIServer waits until the APIServer reports to be healthy, which
+// includes being connected to the Etcd.
+func (f *ControlPlane) waitForAPIServer() error {
+	healthz := *f.APIURL()
+	healthz.Path = "/healthz"
+
+	timedOut := time.After(f.APIServer.StartTimeout)
+	for {
+		res, err := http.Get(healthz.String())
+		if err == nil {
+			res.Body.Close()
+			if res.StatusCode == http.StatusOK {
+				return nil
+			}
+		}
+
+		select {
+		case <-timedOut:
+			return fmt.Errorf("timeout waiting for the APIServer to reconnect to the Etcd")
+		case <-time.After(100 * time.Millisecond):
+		}
+	}
+}
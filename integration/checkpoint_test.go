@@ -0,0 +1,37 @@
+package integration
+
+import (
+	"io/ioutil"
+	"os"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Checkpoints", func() {
+	It("need a started ControlPlane", func() {
+		cp := &ControlPlane{}
+		Expect(cp.Checkpoint("baseline")).To(MatchError(ContainSubstring("needs to be started")))
+		Expect(cp.Rollback("baseline")).To(MatchError(ContainSubstring("needs to be started")))
+	})
+})
+
+var _ = Describe("Etcd snapshots", func() {
+	It("can only be saved from a running Etcd", func() {
+		Expect((&Etcd{}).SaveSnapshot("/some/dir")).To(MatchError(ContainSubstring("needs to be running")))
+	})
+
+	It("are restored when a stopped Etcd starts", func() {
+		dir, err := ioutil.TempDir("", "k8s_test_framework_")
+		Expect(err).NotTo(HaveOccurred())
+		defer os.RemoveAll(dir)
+
+		etcd := &Etcd{}
+		Expect(etcd.RestoreSnapshot(dir)).To(Succeed())
+		Expect(etcd.snapshot).To(Equal(dir))
+	})
+
+	It("cannot be restored if they do not exist", func() {
+		Expect((&Etcd{}).RestoreSnapshot("/does/not/exist")).NotTo(Succeed())
+	})
+})
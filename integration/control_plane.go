@@ -3,19 +3,63 @@ package integration
 import (
 	"fmt"
 	"net/url"
+	"path/filepath"
+	"sync"
+
+	"github.com/kubernetes-sigs/testing_frameworks/integration/internal"
 )
 
 // ControlPlane is a struct that knows how to start your test control plane.
 //
-// Right now, that means Etcd and your APIServer. This is likely to increase in
-// future.
+// That means Etcd and your APIServer, and, if configured, a
+// ControllerManager, a Scheduler, in-process Controllers and Processes of
+// your own, all wired up with the APIServer.
 type ControlPlane struct {
 	APIServer *APIServer
 	Etcd      *Etcd
+
+	// ControllerManager, if configured, is started after the APIServer, and
+	// connects to it with a kubeconfig generated for
+	// "system:kube-controller-manager".
+	ControllerManager *ControllerManager
+
+	// Scheduler, if configured, is started after the APIServer, and connects
+	// to it with a kubeconfig generated for "system:kube-scheduler".
+	Scheduler *Scheduler
+
+	// Controllers, if configured, run in the test process while the
+	// ControlPlane is started. See the Controllers type for details.
+	Controllers *Controllers
+
+	// Processes, if configured, are started last, in order, each with a
+	// kubeconfig generated for its User, unless it has a KubeConfigFile of
+	// its own. They are stopped first, in reverse order.
+	Processes []*Process
+
+	// dir holds the kubeconfig, the HOMEs of the KubeCtls and the
+	// checkpoints. It is removed when the ControlPlane stops.
+	dir string
+
+	// mu guards the fields below, as KubeCtls, Watchers, ObjectRefs and
+	// FakeNodes may be used from several goroutines at once.
+	mu            sync.Mutex
+	kubeCtlCount  int
+	watchers      []*Watcher
+	adminClient   *RESTClient
+	fakeNodes     []*FakeNode
+	fakeNodeCount int
+
+	resetBaseline map[string]bool
+
+	aggregatedAPIServers []*AggregatedAPIServer
 }
 
 // Start will start your control plane processes. To stop them, call Stop().
 func (f *ControlPlane) Start() error {
+	f.mu.Lock()
+	f.adminClient = nil
+	f.mu.Unlock()
+
 	if f.Etcd == nil {
 		f.Etcd = &Etcd{}
 	}
@@ -27,11 +71,123 @@ func (f *ControlPlane) Start() error {
 		f.APIServer = &APIServer{}
 	}
 	f.APIServer.EtcdURL = f.Etcd.URL
-	return f.APIServer.Start()
+	if err := f.APIServer.Start(); err != nil {
+		return err
+	}
+
+	if err := f.writeKubeConfig(); err != nil {
+		return err
+	}
+
+	if f.ControllerManager != nil {
+		if err := f.startControllerManager(); err != nil {
+			return err
+		}
+	}
+
+	if f.Scheduler != nil {
+		if err := f.startScheduler(); err != nil {
+			return err
+		}
+	}
+
+	if f.Controllers != nil {
+		client, err := f.RESTClientAs(controllersUser)
+		if err != nil {
+			return err
+		}
+		f.Controllers.client = client
+		f.Controllers.start()
+	}
+
+	for i, process := range f.Processes {
+		if err := f.startProcess(i, process); err != nil {
+			return err
+		}
+	}
+
+	return f.recordResetBaseline()
+}
+
+// startControllerManager wires the ControllerManager up with the APIServer
+// and starts it.
+func (f *ControlPlane) startControllerManager() error {
+	kubeConfigFile := filepath.Join(f.dir, "controller-manager.kubeconfig")
+	if err := f.writeKubeConfigAs(kubeConfigFile, controllerManagerUser); err != nil {
+		return err
+	}
+
+	f.ControllerManager.KubeConfigFile = kubeConfigFile
+	f.ControllerManager.ServiceAccountKeyFile = filepath.Join(f.APIServer.CertDir, internal.APIServerServiceAccountKeyFile)
+	f.ControllerManager.RootCAFile = f.APIServer.CACertFile()
+	return f.ControllerManager.Start()
+}
+
+// startScheduler wires the Scheduler up with the APIServer and starts it.
+func (f *ControlPlane) startScheduler() error {
+	kubeConfigFile := filepath.Join(f.dir, "scheduler.kubeconfig")
+	if err := f.writeKubeConfigAs(kubeConfigFile, schedulerUser); err != nil {
+		return err
+	}
+
+	f.Scheduler.KubeConfigFile = kubeConfigFile
+	return f.Scheduler.Start()
+}
+
+// StartProcess starts the Process like those configured in Processes, on
+// the already started ControlPlane, e.g. once the CustomResourceDefinitions
+// it needs have been applied. It is added to Processes, to be stopped with
+// the ControlPlane.
+func (f *ControlPlane) StartProcess(process *Process) error {
+	if f.dir == "" || f.APIServer == nil {
+		return fmt.Errorf("the ControlPlane needs to be started before it can start a Process")
+	}
+	if err := f.startProcess(len(f.Processes), process); err != nil {
+		return err
+	}
+	f.Processes = append(f.Processes, process)
+	return nil
+}
+
+// startProcess writes a kubeconfig for the i-th Process, if it does not have
+// one of its own, and starts it.
+func (f *ControlPlane) startProcess(i int, process *Process) error {
+	if process.KubeConfigFile == "" || process.generatedKubeConfig {
+		kubeConfigFile := filepath.Join(f.dir, fmt.Sprintf("process-%d.kubeconfig", i+1))
+		if err := f.writeKubeConfigAs(kubeConfigFile, process.user()); err != nil {
+			return err
+		}
+		process.KubeConfigFile = kubeConfigFile
+		process.generatedKubeConfig = true
+	}
+	return process.Start()
 }
 
 // Stop will stop your control plane processes, and clean up their data.
 func (f *ControlPlane) Stop() error {
+	f.stopWatchers()
+	f.stopFakeNodes()
+	if err := f.stopAggregatedAPIServers(); err != nil {
+		return err
+	}
+	for i := len(f.Processes) - 1; i >= 0; i-- {
+		if err := f.Processes[i].Stop(); err != nil {
+			return err
+		}
+	}
+	if f.Controllers != nil {
+		f.Controllers.stopAndWait()
+	}
+	if f.Scheduler != nil {
+		if err := f.Scheduler.Stop(); err != nil {
+			return err
+		}
+	}
+	if f.ControllerManager != nil {
+		if err := f.ControllerManager.Stop(); err != nil {
+			return err
+		}
+	}
 	if f.APIServer != nil {
 		if err := f.APIServer.Stop(); err != nil {
 			return err
@@ -42,7 +198,7 @@ func (f *ControlPlane) Stop() error {
 			return err
 		}
 	}
-	return nil
+	return f.removeDir()
 }
 
 // APIURL returns the URL you should connect to to talk to your API.
@@ -50,10 +206,83 @@ func (f *ControlPlane) APIURL() *url.URL {
 	return f.APIServer.URL
 }
 
+// SecureAPIURL returns the URL of the APIServer's secure port. Requests to
+// this URL are authenticated and authorized.
+func (f *ControlPlane) SecureAPIURL() *url.URL {
+	return f.APIServer.SecureURL
+}
+
 // KubeCtl returns a pre-configured KubeCtl, ready to connect to this
 // ControlPlane.
 func (f *ControlPlane) KubeCtl() *KubeCtl {
-	k := &KubeCtl{}
+	k := f.newKubeCtl()
 	k.Opts = append(k.Opts, fmt.Sprintf("--server=%s", f.APIURL()))
 	return k
 }
+
+// newKubeCtl returns a KubeCtl with its own HOME in the directory managed by
+// this ControlPlane, so its cache is kept between commands and removed when
+// the ControlPlane stops.
+func (f *ControlPlane) newKubeCtl() *KubeCtl {
+	k := &KubeCtl{}
+	if f.dir != "" {
+		f.mu.Lock()
+		f.kubeCtlCount++
+		k.Dir = filepath.Join(f.dir, fmt.Sprintf("kubectl-%d", f.kubeCtlCount))
+		f.mu.Unlock()
+	}
+	return k
+}
+
+// controllerManagerUser is the user a ControllerManager started by a
+// ControlPlane authenticates as. It is a member of "system:masters", as the
+// controllers do not use ServiceAccount credentials of their own.
+var controllerManagerUser = User{Name: "system:kube-controller-manager", Groups: []string{"system:masters"}}
+
+// schedulerUser is the user a Scheduler started by a ControlPlane
+// authenticates as. It is a member of "system:masters", so extenders and
+// profiles under test are not limited by the Scheduler's default role.
+var schedulerUser = User{Name: "system:kube-scheduler", Groups: []string{"system:masters"}}
+
+// User is an identity which can authenticate against the secure port of the
+// APIServer.
+type User struct {
+	// Name is the name of the user, e.g. "jane".
+	Name string
+
+	// Groups are the groups the user is a member of, e.g. "system:masters".
+	Groups []string
+}
+
+// KubeCtlAs returns a KubeCtl which connects to the secure port of this
+// ControlPlane and authenticates as the given user, by means of a client
+// certificate. The certificate is issued once per user name and groups, and
+// shared by all KubeCtls for that user.
+func (f *ControlPlane) KubeCtlAs(user User) (*KubeCtl, error) {
+	certFile, keyFile, err := f.APIServer.issueClientCert(user)
+	if err != nil {
+		return nil, err
+	}
+
+	k := f.newKubeCtl()
+	k.Opts = append(k.Opts,
+		fmt.Sprintf("--server=%s", f.SecureAPIURL()),
+		fmt.Sprintf("--certificate-authority=%s", f.APIServer.CACertFile()),
+		fmt.Sprintf("--client-certificate=%s", certFile),
+		fmt.Sprintf("--client-key=%s", keyFile),
+	)
+	return k, nil
+}
+
+// KubeCtlWithToken returns a KubeCtl which connects to the secure port of this
+// ControlPlane and authenticates with the bearer token, e.g. an ID token
+// minted by an OIDCIssuer.
+func (f *ControlPlane) KubeCtlWithToken(token string) *KubeCtl {
+	k := f.newKubeCtl()
+	k.Opts = append(k.Opts,
+		fmt.Sprintf("--server=%s", f.SecureAPIURL()),
+		fmt.Sprintf("--certificate-authority=%s", f.APIServer.CACertFile()),
+		fmt.Sprintf("--token=%s", token),
+	)
+	return k
+}
@@ -0,0 +1,65 @@
+package integration
+
+import (
+	"net/url"
+	"sync"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/kubernetes-sigs/testing_frameworks/integration/internal"
+)
+
+var _ = Describe("ControlPlane", func() {
+	It("gives KubeCtls created concurrently a HOME of their own", func() {
+		cp := &ControlPlane{dir: "/some/dir"}
+
+		dirs := make(chan string, 10)
+		wg := sync.WaitGroup{}
+		for i := 0; i < cap(dirs); i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				dirs <- cp.newKubeCtl().Dir
+			}()
+		}
+		wg.Wait()
+		close(dirs)
+
+		seen := map[string]bool{}
+		for dir := range dirs {
+			Expect(seen).NotTo(HaveKey(dir))
+			seen[dir] = true
+		}
+		Expect(seen).To(HaveLen(cap(dirs)))
+	})
+
+	It("shares one admin RESTClient between goroutines", func() {
+		ca, err := internal.NewTinyCA("apiserver-ca")
+		Expect(err).NotTo(HaveOccurred())
+		cp := &ControlPlane{APIServer: &APIServer{
+			SecureURL: &url.URL{Scheme: "https", Host: "127.0.0.1:1234"},
+			ca:        ca,
+		}}
+
+		clients := make(chan *RESTClient, 10)
+		wg := sync.WaitGroup{}
+		for i := 0; i < cap(clients); i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				defer GinkgoRecover()
+				client, err := cp.adminRESTClient()
+				Expect(err).NotTo(HaveOccurred())
+				clients <- client
+			}()
+		}
+		wg.Wait()
+		close(clients)
+
+		first := <-clients
+		for client := range clients {
+			Expect(client).To(BeIdenticalTo(first))
+		}
+	})
+})
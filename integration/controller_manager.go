@@ -0,0 +1,179 @@
+package integration
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/kubernetes-sigs/testing_frameworks/integration/internal"
+)
+
+// ControllerManager knows how to run a kube-controller-manager, which runs
+// the controllers a cluster relies on, e.g. the garbage collector, the
+// namespace controller, or the controllers rolling out Deployments.
+//
+// Configure one on a ControlPlane to have it started after, and stopped
+// before, the APIServer, with everything it needs to connect to it.
+type ControllerManager struct {
+	// SecureURL is the address the ControllerManager should serve its health
+	// checks on. It is served over TLS, with a certificate issued by a CA
+	// generated when the ControllerManager starts.
+	//
+	// If this is not specified, we default to a random free port on localhost.
+	SecureURL *url.URL
+
+	// Path is the path to the kube-controller-manager binary.
+	//
+	// If this is left as the empty string, we will attempt to locate a binary,
+	// by checking for the TEST_ASSET_KUBE_CONTROLLER_MANAGER environment
+	// variable, and the default test assets directory. See the "Binaries"
+	// section above (in doc.go) for details.
+	Path string
+
+	// Args is a list of arguments which will passed to the ControllerManager
+	// binary. Before they are passed on, they will be evaluated as go-template
+	// strings. This means you can use fields which are defined and exported on
+	// this ControllerManager struct (e.g. "--kubeconfig={{ .KubeConfigFile }}").
+	// Those templates will be evaluated after the defaulting of the
+	// ControllerManager's fields has already happened and just before the
+	// binary actually gets started.
+	//
+	// If not specified, the minimal set of arguments to run the
+	// ControllerManager will be used.
+	Args []string
+
+	// CertDir is a path to a directory containing the ControllerManager's
+	// serving certificate.
+	//
+	// If left unspecified, then the Start() method will create a fresh temporary
+	// directory, and the Stop() method will clean it up.
+	CertDir string
+
+	// KubeConfigFile is the path to the kubeconfig the ControllerManager uses
+	// to connect to the APIServer.
+	//
+	// If this is not specified, the Start() method will return an error. A
+	// ControlPlane generates one, authenticating as
+	// "system:kube-controller-manager".
+	KubeConfigFile string
+
+	// ServiceAccountKeyFile is the path to the key the ControllerManager signs
+	// the tokens of ServiceAccount Secrets with, and RootCAFile the path to the
+	// CA certificate it puts into those Secrets. A ControlPlane sets them to
+	// the APIServer's ServiceAccount key and CA, so the tokens can be used to
+	// authenticate against the APIServer.
+	//
+	// If not specified, the tokens controller does not run.
+	ServiceAccountKeyFile string
+	RootCAFile            string
+
+	// StartTimeout, StopTimeout specify the time the ControllerManager is
+	// allowed to take when starting and stopping before an error is emitted.
+	//
+	// If not specified, these default to 20 seconds.
+	StartTimeout time.Duration
+	StopTimeout  time.Duration
+
+	// Out, Err specify where ControllerManager should write its StdOut, StdErr
+	// to.
+	//
+	// If not specified, the output will be discarded.
+	Out io.Writer
+	Err io.Writer
+
+	processState *internal.ProcessState
+	ca           *internal.TinyCA
+}
+
+// Start starts the controller manager, waits for it to come up, and returns
+// an error, if occurred.
+func (m *ControllerManager) Start() error {
+	if m.KubeConfigFile == "" {
+		return fmt.Errorf("expected KubeConfigFile to be configured")
+	}
+
+	var err error
+	if m.SecureURL == nil {
+		m.SecureURL, err = internal.NewLocalURL("https")
+		if err != nil {
+			return err
+		}
+	}
+
+	m.processState = &internal.ProcessState{}
+
+	m.processState.DefaultedProcessInput, err = internal.DoDefaulting(
+		"kube-controller-manager",
+		m.SecureURL,
+		m.CertDir,
+		m.Path,
+		m.StartTimeout,
+		m.StopTimeout,
+	)
+	if err != nil {
+		return err
+	}
+
+	m.CertDir = m.processState.Dir
+	m.Path = m.processState.Path
+	m.StartTimeout = m.processState.StartTimeout
+	m.StopTimeout = m.processState.StopTimeout
+
+	if m.ca == nil {
+		m.ca, err = internal.NewTinyCA("integration-controller-manager-ca")
+		if err != nil {
+			return err
+		}
+	}
+	err = internal.WriteServingCert(m.CertDir, m.ca,
+		internal.ControllerManagerServingCertFile, internal.ControllerManagerServingKeyFile,
+		m.SecureURL.Hostname())
+	if err != nil {
+		return err
+	}
+
+	m.processState.HealthCheckEndpoint = "/healthz"
+	m.processState.HealthCheckClient = healthCheckClient(m.ca)
+
+	m.processState.Args, err = internal.RenderTemplates(
+		internal.DoControllerManagerArgDefaulting(m.Args), m,
+	)
+	if err != nil {
+		return err
+	}
+
+	return m.processState.Start(m.Out, m.Err)
+}
+
+// Stop stops this process gracefully, waits for its termination, and cleans up
+// the CertDir if necessary.
+func (m *ControllerManager) Stop() error {
+	if m.processState == nil {
+		return nil
+	}
+	return m.processState.Stop()
+}
+
+// restart stops the ControllerManager, calls whileStopped, and starts it
+// again, e.g. to have it start over after the APIServer's state changed under
+// its caches.
+func (m *ControllerManager) restart(whileStopped func() error) error {
+	if m.processState == nil {
+		return whileStopped()
+	}
+	return m.processState.Restart(m.Out, m.Err, whileStopped)
+}
+
+// healthCheckClient returns a client for the health checks of a process
+// serving them with a certificate issued by the CA.
+func healthCheckClient(ca *internal.TinyCA) *http.Client {
+	pool := x509.NewCertPool()
+	pool.AppendCertsFromPEM(ca.CertBytes())
+	return &http.Client{
+		Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: pool}},
+	}
+}
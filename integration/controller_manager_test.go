@@ -0,0 +1,41 @@
+package integration
+
+import (
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/kubernetes-sigs/testing_frameworks/integration/internal"
+)
+
+var _ = Describe("ControllerManager", func() {
+	It("needs a kubeconfig", func() {
+		Expect((&ControllerManager{}).Start()).To(MatchError(ContainSubstring("KubeConfigFile")))
+	})
+
+	It("can be stopped without having been started", func() {
+		Expect((&ControllerManager{}).Stop()).To(Succeed())
+	})
+
+	It("is configured to serve its health checks securely, and to connect to the APIServer", func() {
+		m := &ControllerManager{
+			Path:                  "/does/not/exist/kube-controller-manager",
+			KubeConfigFile:        "/some/kubeconfig",
+			ServiceAccountKeyFile: "/some/sa.key",
+			RootCAFile:            "/some/ca.crt",
+		}
+		Expect(m.Start()).NotTo(Succeed())
+		defer os.RemoveAll(m.CertDir)
+
+		Expect(m.SecureURL.Scheme).To(Equal("https"))
+		Expect(filepath.Join(m.CertDir, internal.ControllerManagerServingCertFile)).To(BeAnExistingFile())
+		Expect(filepath.Join(m.CertDir, internal.ControllerManagerServingKeyFile)).To(BeAnExistingFile())
+		Expect(m.processState.HealthCheckEndpoint).To(Equal("/healthz"))
+		Expect(m.processState.Args).To(ContainElement("--kubeconfig=/some/kubeconfig"))
+		Expect(m.processState.Args).To(ContainElement("--secure-port=" + m.SecureURL.Port()))
+		Expect(m.processState.Args).To(ContainElement("--service-account-private-key-file=/some/sa.key"))
+		Expect(m.processState.Args).To(ContainElement("--root-ca-file=/some/ca.crt"))
+	})
+})
@@ -0,0 +1,223 @@
+package integration
+
+import (
+	"sync"
+	"time"
+)
+
+// controllersUser is the user the Controllers of a ControlPlane authenticate
+// as.
+var controllersUser = User{Name: "system:integration-controllers", Groups: []string{"system:masters"}}
+
+// Controllers are small stand-ins, running in the test process, for the
+// controllers of a kube-controller-manager which most tests rely on, without
+// having to run a ControllerManager. Each of them can be enabled on its own:
+//
+//	cp := &integration.ControlPlane{
+//		Controllers: &integration.Controllers{Namespaces: true, ServiceAccounts: true},
+//	}
+//
+// They poll the APIServer as a member of "system:masters", so changes are
+// picked up within the Interval, or the GarbageCollectorInterval, not right
+// away. Use Eventually to wait for them.
+type Controllers struct {
+	// Namespaces enables finalizing namespaces which are being deleted: Their
+	// contents are deleted, without a grace period, and once no objects with
+	// finalizers are left, the namespace is deleted for good.
+	Namespaces bool
+
+	// ServiceAccounts enables creating the "default" ServiceAccount in every
+	// namespace.
+	ServiceAccounts bool
+
+	// GarbageCollector enables deleting objects all of whose owners, in their
+	// metadata.ownerReferences, are gone. Owners deleted with the "Foreground"
+	// or "Orphan" propagation policy are deleted once their dependents are
+	// deleted or orphaned, respectively.
+	GarbageCollector bool
+
+	// Interval is the time between two runs of each controller, except for
+	// the GarbageCollector.
+	//
+	// If not specified, this defaults to 200 milliseconds.
+	Interval time.Duration
+
+	// GarbageCollectorInterval is the time between two runs of the
+	// GarbageCollector, which lists the objects of every resource on each
+	// run.
+	//
+	// If not specified, this defaults to 1 second.
+	GarbageCollectorInterval time.Duration
+
+	client *RESTClient
+	stop   chan struct{}
+	wg     sync.WaitGroup
+
+	mu   sync.Mutex
+	errs []error
+}
+
+// start starts the enabled controllers in the background, using the client.
+func (c *Controllers) start() {
+	if c.Interval == 0 {
+		c.Interval = 200 * time.Millisecond
+	}
+	if c.GarbageCollectorInterval == 0 {
+		c.GarbageCollectorInterval = time.Second
+	}
+	c.stop = make(chan struct{})
+	c.mu.Lock()
+	c.errs = nil
+	c.mu.Unlock()
+
+	if c.Namespaces {
+		c.run(c.finalizeNamespaces, c.Interval)
+	}
+	if c.ServiceAccounts {
+		c.run(c.createServiceAccounts, c.Interval)
+	}
+	if c.GarbageCollector {
+		c.run(c.collectGarbage, c.GarbageCollectorInterval)
+	}
+}
+
+// stopAndWait stops the controllers and waits for their current runs to
+// finish.
+func (c *Controllers) stopAndWait() {
+	if c.stop == nil {
+		return
+	}
+	close(c.stop)
+	c.wg.Wait()
+	c.stop = nil
+}
+
+// run runs the controller every interval, until the controllers are stopped,
+// and keeps the error of its last run.
+func (c *Controllers) run(controller func() error, interval time.Duration) {
+	c.mu.Lock()
+	c.errs = append(c.errs, nil)
+	i := len(c.errs) - 1
+	c.mu.Unlock()
+
+	c.wg.Add(1)
+	go func() {
+		defer c.wg.Done()
+		for {
+			err := controller()
+			c.mu.Lock()
+			c.errs[i] = err
+			c.mu.Unlock()
+
+			select {
+			case <-c.stop:
+				return
+			case <-time.After(interval):
+			}
+		}
+	}()
+}
+
+// Err returns the error the last run of any of the controllers ran into, if
+// any. The controllers keep running nevertheless, and a controller's error is
+// cleared by its next successful run.
+func (c *Controllers) Err() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, err := range c.errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// finalizeNamespaces deletes the contents of namespaces which are being
+// deleted, and finalizes those without any contents left.
+func (c *Controllers) finalizeNamespaces() error {
+	namespaces, err := c.client.List(namespacesResource, "")
+	if err != nil {
+		return err
+	}
+
+	for _, namespace := range namespaces.Items() {
+		if _, deleting := namespace.Field("metadata.deletionTimestamp"); !deleting {
+			continue
+		}
+		n := &Namespace{Name: namespace.Name(), client: c.client}
+		remaining, err := n.deleteContents(false)
+		if err != nil {
+			return err
+		}
+		if remaining > 0 {
+			continue
+		}
+		if err := n.finalize(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+var serviceAccountsResource = GroupVersionResource{Version: "v1", Resource: "serviceaccounts"}
+
+// createServiceAccounts creates the "default" ServiceAccount in namespaces
+// without one.
+func (c *Controllers) createServiceAccounts() error {
+	namespaces, err := c.client.List(namespacesResource, "")
+	if err != nil {
+		return err
+	}
+	serviceAccounts, err := c.client.List(serviceAccountsResource, "")
+	if err != nil {
+		return err
+	}
+
+	hasDefault := map[string]bool{}
+	for _, serviceAccount := range serviceAccounts.Items() {
+		if serviceAccount.Name() == "default" {
+			hasDefault[serviceAccount.Namespace()] = true
+		}
+	}
+
+	for _, namespace := range namespaces.Items() {
+		if _, deleting := namespace.Field("metadata.deletionTimestamp"); deleting || hasDefault[namespace.Name()] {
+			continue
+		}
+		_, err := c.client.Create(serviceAccountsResource, namespace.Name(), Object{
+			"apiVersion": "v1",
+			"kind":       "ServiceAccount",
+			"metadata":   map[string]interface{}{"name": "default"},
+		})
+		if err == nil || IsAlreadyExists(err) || IsNotFound(err) {
+			continue
+		}
+		// The namespace might have started terminating in the meantime,
+		// which forbids creating anything in it.
+		if hasReason(err, "Forbidden") {
+			terminating, getErr := c.namespaceTerminating(namespace.Name())
+			if getErr != nil {
+				return getErr
+			}
+			if terminating {
+				continue
+			}
+		}
+		return err
+	}
+	return nil
+}
+
+// namespaceTerminating returns whether the namespace is being deleted, or is
+// gone already.
+func (c *Controllers) namespaceTerminating(name string) (bool, error) {
+	namespace, err := c.client.Get(namespacesResource, "", name)
+	if IsNotFound(err) {
+		return true, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	_, deleting := namespace.Field("metadata.deletionTimestamp")
+	return deleting, nil
+}
@@ -0,0 +1,251 @@
+package integration
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync/atomic"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/ghttp"
+
+	"github.com/kubernetes-sigs/testing_frameworks/integration/internal"
+)
+
+var _ = Describe("Controllers", func() {
+	var (
+		server      *recordingServer
+		controllers *Controllers
+	)
+
+	BeforeEach(func() {
+		server = newRecordingServer()
+		controllers = &Controllers{client: server.client}
+
+		server.RouteToHandler("GET", "/apis", ghttp.RespondWith(http.StatusOK, `{"groups": []}`))
+		server.RouteToHandler("GET", "/api/v1", ghttp.RespondWith(http.StatusOK, `{
+			"groupVersion": "v1",
+			"resources": [
+				{"name": "configmaps", "kind": "ConfigMap", "namespaced": true, "verbs": ["create", "delete", "get", "list"]},
+				{"name": "namespaces", "kind": "Namespace", "namespaced": false, "verbs": ["create", "delete", "get", "list"]}
+			]
+		}`))
+		server.RouteToHandler("GET", "/api/v1/configmaps", ghttp.RespondWith(http.StatusOK, `{"items": []}`))
+		server.RouteToHandler("GET", "/api/v1/namespaces", ghttp.RespondWith(http.StatusOK, `{"items": []}`))
+	})
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("run until they are stopped, and remember their last error", func() {
+		var runs int32
+		errSomething := fmt.Errorf("something failed")
+		controllers.stop = make(chan struct{})
+		controllers.run(func() error {
+			atomic.AddInt32(&runs, 1)
+			return errSomething
+		}, 10*time.Millisecond)
+		Eventually(controllers.Err).Should(Equal(errSomething))
+
+		controllers.stopAndWait()
+		stoppedAt := atomic.LoadInt32(&runs)
+		Consistently(func() int32 { return atomic.LoadInt32(&runs) }, "50ms").Should(Equal(stoppedAt))
+	})
+
+	It("forget a controller's error once it runs successfully again", func() {
+		var runs int32
+		controllers.stop = make(chan struct{})
+		defer controllers.stopAndWait()
+		controllers.run(func() error {
+			if atomic.AddInt32(&runs, 1) == 1 {
+				return fmt.Errorf("something failed")
+			}
+			return nil
+		}, 10*time.Millisecond)
+		controllers.run(func() error { return nil }, 10*time.Millisecond)
+
+		Eventually(func() int32 { return atomic.LoadInt32(&runs) }).Should(BeNumerically(">", 1))
+		Eventually(controllers.Err).Should(BeNil())
+	})
+
+	It("are stopped during Reset, and started again afterwards", func() {
+		etcd := ghttp.NewServer()
+		defer etcd.Close()
+		etcd.RouteToHandler("POST", "/v3/maintenance/status", ghttp.RespondWith(http.StatusOK, `{}`))
+		etcd.RouteToHandler("POST", "/v3/kv/range", ghttp.RespondWith(http.StatusOK, `{"kvs": []}`))
+		etcdURL, err := url.Parse(etcd.URL())
+		Expect(err).NotTo(HaveOccurred())
+
+		controllers.Namespaces = true
+		controllers.Interval = time.Hour
+		controllers.start()
+		defer controllers.stopAndWait()
+		stopped := controllers.stop
+
+		cp := &ControlPlane{
+			Etcd:        &Etcd{client: &internal.EtcdClient{URL: *etcdURL}},
+			Controllers: controllers,
+		}
+		Expect(cp.Reset()).To(Succeed())
+		Expect(stopped).To(BeClosed())
+		Expect(controllers.stop).NotTo(BeNil())
+		Expect(controllers.stop).NotTo(BeClosed())
+	})
+
+	Describe("ServiceAccounts", func() {
+		It("creates the default ServiceAccount in namespaces without one", func() {
+			server.RouteToHandler("GET", "/api/v1/namespaces", ghttp.RespondWith(http.StatusOK, `{"items": [
+				{"metadata": {"name": "has-one"}},
+				{"metadata": {"name": "new"}},
+				{"metadata": {"name": "deleted", "deletionTimestamp": "2018-06-01T00:00:00Z"}}
+			]}`))
+			server.RouteToHandler("GET", "/api/v1/serviceaccounts", ghttp.RespondWith(http.StatusOK, `{"items": [
+				{"metadata": {"namespace": "has-one", "name": "default"}},
+				{"metadata": {"namespace": "new", "name": "other"}}
+			]}`))
+			server.RouteToHandler("POST", "/api/v1/namespaces/new/serviceaccounts", server.record(http.StatusOK, `{}`))
+
+			Expect(controllers.createServiceAccounts()).To(Succeed())
+			Expect(server.recordedWithBodies()).To(Equal([]string{
+				`POST /api/v1/namespaces/new/serviceaccounts {"apiVersion":"v1","kind":"ServiceAccount","metadata":{"name":"default"}}`,
+			}))
+		})
+
+		Context("when creating the ServiceAccount is forbidden", func() {
+			BeforeEach(func() {
+				server.RouteToHandler("GET", "/api/v1/namespaces", ghttp.RespondWith(http.StatusOK, `{"items": [
+					{"metadata": {"name": "new"}}
+				]}`))
+				server.RouteToHandler("GET", "/api/v1/serviceaccounts", ghttp.RespondWith(http.StatusOK, `{"items": []}`))
+				server.RouteToHandler("POST", "/api/v1/namespaces/new/serviceaccounts",
+					ghttp.RespondWith(http.StatusForbidden, `{"kind": "Status", "reason": "Forbidden", "message": "not allowed"}`))
+			})
+
+			It("skips namespaces which started terminating in the meantime", func() {
+				server.RouteToHandler("GET", "/api/v1/namespaces/new", ghttp.RespondWith(http.StatusOK,
+					`{"metadata": {"name": "new", "deletionTimestamp": "2018-06-01T00:00:00Z"}}`))
+
+				Expect(controllers.createServiceAccounts()).To(Succeed())
+			})
+
+			It("returns the error otherwise", func() {
+				server.RouteToHandler("GET", "/api/v1/namespaces/new", ghttp.RespondWith(http.StatusOK, `{"metadata": {"name": "new"}}`))
+
+				err := controllers.createServiceAccounts()
+				Expect(err).To(HaveOccurred())
+				Expect(err.(*StatusError).Reason).To(Equal("Forbidden"))
+			})
+		})
+	})
+
+	Describe("Namespaces", func() {
+		It("deletes the contents of deleted namespaces, and finalizes them once they are empty", func() {
+			server.RouteToHandler("GET", "/api/v1/namespaces", ghttp.RespondWith(http.StatusOK, `{"items": [
+				{"metadata": {"name": "active"}},
+				{"metadata": {"name": "empty", "deletionTimestamp": "2018-06-01T00:00:00Z"}},
+				{"metadata": {"name": "blocked", "deletionTimestamp": "2018-06-01T00:00:00Z"}}
+			]}`))
+			server.RouteToHandler("GET", "/api/v1/namespaces/empty/configmaps", ghttp.RespondWith(http.StatusOK, `{"items": [
+				{"metadata": {"name": "plain"}}
+			]}`))
+			server.RouteToHandler("GET", "/api/v1/namespaces/blocked/configmaps", ghttp.RespondWith(http.StatusOK, `{"items": [
+				{"metadata": {"name": "finalized", "finalizers": ["example.com/cleanup"], "deletionTimestamp": "2018-06-01T00:00:00Z"}}
+			]}`))
+			server.RouteToHandler("GET", "/api/v1/namespaces/empty", ghttp.RespondWith(http.StatusOK, `{"metadata": {"name": "empty"}, "spec": {"finalizers": ["kubernetes"]}}`))
+			server.RouteToHandler("DELETE", "/api/v1/namespaces/empty/configmaps/plain", server.record(http.StatusOK, `{}`))
+			server.RouteToHandler("PUT", "/api/v1/namespaces/empty/finalize", server.record(http.StatusOK, `{}`))
+			server.RouteToHandler("DELETE", "/api/v1/namespaces/empty", server.record(http.StatusOK, `{}`))
+
+			Expect(controllers.finalizeNamespaces()).To(Succeed())
+			Expect(server.recordedWithBodies()).To(Equal([]string{
+				"DELETE /api/v1/namespaces/empty/configmaps/plain?gracePeriodSeconds=0",
+				`PUT /api/v1/namespaces/empty/finalize {"metadata":{"name":"empty"},"spec":{"finalizers":[]}}`,
+				"DELETE /api/v1/namespaces/empty",
+			}))
+		})
+	})
+
+	Describe("GarbageCollector", func() {
+		It("deletes orphaned dependents, and finishes foreground and orphaning deletions", func() {
+			server.RouteToHandler("GET", "/api/v1/configmaps", ghttp.RespondWith(http.StatusOK, `{"items": [
+				{"metadata": {"namespace": "default", "name": "owner", "uid": "1"}},
+				{"metadata": {"namespace": "default", "name": "owned", "uid": "2", "ownerReferences": [
+					{"apiVersion": "v1", "kind": "ConfigMap", "name": "owner", "uid": "1"}
+				]}},
+				{"metadata": {"namespace": "default", "name": "orphan", "uid": "3", "ownerReferences": [
+					{"apiVersion": "v1", "kind": "ConfigMap", "name": "gone", "uid": "4"}
+				]}},
+				{"metadata": {"namespace": "default", "name": "recreated-owner", "uid": "5", "ownerReferences": [
+					{"apiVersion": "v1", "kind": "ConfigMap", "name": "new", "uid": "6"}
+				]}},
+				{"metadata": {"namespace": "default", "name": "unknown-owner", "uid": "7", "ownerReferences": [
+					{"apiVersion": "example.com/v1", "kind": "Widget", "name": "gone", "uid": "8"}
+				]}},
+				{"metadata": {"namespace": "default", "name": "foreground", "uid": "10", "resourceVersion": "100",
+					"deletionTimestamp": "2018-06-01T00:00:00Z", "finalizers": ["foregroundDeletion"]}},
+				{"metadata": {"namespace": "default", "name": "foreground-dependent", "uid": "11", "ownerReferences": [
+					{"apiVersion": "v1", "kind": "ConfigMap", "name": "foreground", "uid": "10"}
+				]}},
+				{"metadata": {"namespace": "default", "name": "orphaning", "uid": "20", "resourceVersion": "200",
+					"deletionTimestamp": "2018-06-01T00:00:00Z", "finalizers": ["orphan", "example.com/other"]}},
+				{"metadata": {"namespace": "default", "name": "orphaning-dependent", "uid": "21", "resourceVersion": "210", "ownerReferences": [
+					{"apiVersion": "v1", "kind": "ConfigMap", "name": "orphaning", "uid": "20", "controller": true}
+				]}}
+			]}`))
+			server.RouteToHandler("GET", "/api/v1/namespaces/default/configmaps/gone", ghttp.RespondWith(http.StatusNotFound, `{"kind": "Status", "reason": "NotFound"}`))
+			server.RouteToHandler("GET", "/api/v1/namespaces/default/configmaps/new", ghttp.RespondWith(http.StatusOK, `{"metadata": {"name": "new", "uid": "6"}}`))
+			server.RouteToHandler("DELETE", "/api/v1/namespaces/default/configmaps/orphan", server.record(http.StatusOK, `{}`))
+			server.RouteToHandler("DELETE", "/api/v1/namespaces/default/configmaps/foreground-dependent", server.record(http.StatusOK, `{}`))
+			server.RouteToHandler("PATCH", "/api/v1/namespaces/default/configmaps/orphaning-dependent", server.record(http.StatusOK, `{}`))
+			server.RouteToHandler("PATCH", "/api/v1/namespaces/default/configmaps/orphaning", server.record(http.StatusOK, `{}`))
+
+			Expect(controllers.collectGarbage()).To(Succeed())
+			Expect(server.recordedWithBodies()).To(Equal([]string{
+				`DELETE /api/v1/namespaces/default/configmaps/orphan {"apiVersion":"v1","kind":"DeleteOptions","propagationPolicy":"Background"}`,
+				`DELETE /api/v1/namespaces/default/configmaps/foreground-dependent {"apiVersion":"v1","kind":"DeleteOptions","propagationPolicy":"Background"}`,
+				`PATCH /api/v1/namespaces/default/configmaps/orphaning-dependent {"metadata":{"ownerReferences":[],"resourceVersion":"210"}}`,
+				`PATCH /api/v1/namespaces/default/configmaps/orphaning {"metadata":{"finalizers":["example.com/other"],"resourceVersion":"200"}}`,
+			}))
+		})
+
+		It("removes the foregroundDeletion finalizer once all dependents are gone", func() {
+			server.RouteToHandler("GET", "/api/v1/configmaps", ghttp.RespondWith(http.StatusOK, `{"items": [
+				{"metadata": {"namespace": "default", "name": "foreground", "uid": "10", "resourceVersion": "100",
+					"deletionTimestamp": "2018-06-01T00:00:00Z", "finalizers": ["foregroundDeletion"]}}
+			]}`))
+			server.RouteToHandler("PATCH", "/api/v1/namespaces/default/configmaps/foreground", server.record(http.StatusOK, `{}`))
+
+			Expect(controllers.collectGarbage()).To(Succeed())
+			Expect(server.recordedWithBodies()).To(Equal([]string{
+				`PATCH /api/v1/namespaces/default/configmaps/foreground {"metadata":{"finalizers":[],"resourceVersion":"100"}}`,
+			}))
+		})
+
+		It("carries on without unavailable group versions, but keeps deleting owners waiting", func() {
+			server.RouteToHandler("GET", "/apis", ghttp.RespondWith(http.StatusOK, `{"groups": [
+				{"preferredVersion": {"groupVersion": "wardle.example.com/v1alpha1"}}
+			]}`))
+			server.RouteToHandler("GET", "/apis/wardle.example.com/v1alpha1", ghttp.RespondWith(http.StatusServiceUnavailable, "service unavailable"))
+			server.RouteToHandler("GET", "/api/v1/configmaps", ghttp.RespondWith(http.StatusOK, `{"items": [
+				{"metadata": {"namespace": "default", "name": "orphan", "uid": "3", "ownerReferences": [
+					{"apiVersion": "v1", "kind": "ConfigMap", "name": "gone", "uid": "4"}
+				]}},
+				{"metadata": {"namespace": "default", "name": "flunder-owned", "uid": "5", "ownerReferences": [
+					{"apiVersion": "wardle.example.com/v1alpha1", "kind": "Flunder", "name": "unknown", "uid": "6"}
+				]}},
+				{"metadata": {"namespace": "default", "name": "foreground", "uid": "10", "resourceVersion": "100",
+					"deletionTimestamp": "2018-06-01T00:00:00Z", "finalizers": ["foregroundDeletion"]}}
+			]}`))
+			server.RouteToHandler("GET", "/api/v1/namespaces/default/configmaps/gone", ghttp.RespondWith(http.StatusNotFound, `{"kind": "Status", "reason": "NotFound"}`))
+			server.RouteToHandler("DELETE", "/api/v1/namespaces/default/configmaps/orphan", server.record(http.StatusOK, `{}`))
+
+			err := controllers.collectGarbage()
+			Expect(err).To(MatchError(ContainSubstring("wardle.example.com/v1alpha1")))
+			Expect(server.recordedWithBodies()).To(Equal([]string{
+				`DELETE /api/v1/namespaces/default/configmaps/orphan {"apiVersion":"v1","kind":"DeleteOptions","propagationPolicy":"Background"}`,
+			}))
+		})
+	})
+})
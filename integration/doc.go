@@ -28,8 +28,10 @@ Currently the framework provides the following components:
 ControlPlane: The ControlPlane wraps Etcd & APIServer (see below) and wires
 them together correctly. A ControlPlane can be stopped & started and can
 provide the URL to connect to the API. The ControlPlane can also be asked for a
-KubeCtl which is already correctly configured for this ControlPlane. The
-ControlPlane is a good entry point for default setups.
+KubeCtl which is already correctly configured for this ControlPlane, and it
+writes a kubeconfig for other tools or client-go based code, see
+`ControlPlane.KubeConfigFile()`. The ControlPlane is a good entry point for
+default setups.
 
 Etcd: Manages an Etcd binary, which can be started, stopped and connected to.
 By default Etcd will listen on a random port for http connections and will
@@ -38,17 +40,191 @@ Etcd type documentation below.
 
 APIServer: Manages an Kube-APIServer binary, which can be started, stopped and
 connected to. By default APIServer will listen on a random port for http
-connections and will create a temporary directory to store the (auto-generated)
-certificates.  To configure it differently, see the APIServer type
-documentation below.
+connections, on another random port for https connections and will create a
+temporary directory to store the (auto-generated) certificates.  To configure
+it differently, see the APIServer type documentation below.
+
+ControllerManager: Manages a kube-controller-manager binary. It is optional;
+configure one on the ControlPlane to have the garbage collector, namespace
+deletion, Deployment rollouts and the other built-in controllers running. The
+ControlPlane starts it after the APIServer, with a kubeconfig and the
+APIServer's ServiceAccount key.
+
+Scheduler: Manages a kube-scheduler binary. It is optional, too; configure one
+on the ControlPlane to have Pods scheduled, e.g. to test scheduler extenders
+or profiles given in its templated `Config`. The ControlPlane starts it after
+the APIServer with a kubeconfig, and stops it before the APIServer.
+
+Controllers: Lightweight stand-ins for the most basic controllers of a
+kube-controller-manager, running in the test process: finishing the deletion
+of namespaces, creating "default" ServiceAccounts, and garbage collecting
+objects whose owners are gone. Each of them can be enabled on its own.
+
+Process: Manages a binary of your own, e.g. a controller or an extension
+apiserver under test, with templated `Args` and `Env`, a temporary `Dir`, a
+health check or start message to wait for, and its output captured in `Out`
+and `Err`. Configured on the ControlPlane, or started on a running one with
+`ControlPlane.StartProcess(...)`, it is given a kubeconfig for the APIServer,
+in `KubeConfigFile` and the KUBECONFIG environment variable.
 
 KubeCtl: Wraps around a `kubectl` binary and can `Run(...)` arbitrary commands
-against a kubernetes control plane.
+against a kubernetes control plane. Commands run in an isolated environment,
+unaffected by the developer's KUBECONFIG, and can be given a context or stdin
+via `RunContext(...)` and `RunWithStdin(...)`. Long-running commands, like
+watches, can be started with `Start(...)` and their output matched while they
+are still running. To assert on objects, `GetObject(...)` and `GetJSON(...)`
+decode the output of `kubectl ... --output json`, and `HaveField(path, ...)`
+matches fields of the decoded Objects.
+
+REST Client
+
+For tests which need to talk to the API directly, without kubectl,
+`ControlPlane.RESTClient()` returns a minimal client which works with any
+resource as an Object, and returns the Status the APIServer responded with as
+a StatusError:
+
+	client, err := cp.RESTClient()
+	deployments := integration.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"}
+	deployment, err := client.Get(deployments, "default", "my-deployment")
+
+To assert on the sequence of changes, rather than on the final state,
+`ControlPlane.Watch(...)` watches a resource and the ReceiveEvent matcher
+checks the received events, in order:
+
+	watcher, err := cp.Watch(deployments, "default", integration.WatchOptions{})
+	Eventually(watcher).Should(integration.ReceiveEvent(integration.EventAdded))
+
+Watchers are stopped when the ControlPlane stops, and by Reset and Rollback.
+
+To poll the state of objects, `ControlPlane.Ref(...)` references an object
+by "namespace/name", and the matchers Exist, BeDeleted, HaveField,
+HaveCondition and HaveOwnerReference fetch it on every poll:
+
+	web := cp.Ref(deployments, "default/web")
+	Eventually(web).Should(integration.HaveCondition("Available", "True"))
+
+Fixtures
+
+To load fixtures, `ControlPlane.ApplyManifests(paths...)` applies the YAML or
+JSON manifests in files or directories, CustomResourceDefinitions and
+Namespaces first. The returned AppliedManifests can delete the objects it
+created again, keeping those which existed before:
+
+	fixtures, err := cp.ApplyManifests("testdata/fixtures")
+	defer fixtures.Delete()
+
+Sharing a ControlPlane between specs is a lot faster than starting one per
+spec. To keep specs from seeing each other's objects, call
+`ControlPlane.Reset()` after each spec. It deletes all objects created since
+the ControlPlane started straight from Etcd.
+
+If the fixtures themselves are expensive to apply, save them once with
+`ControlPlane.Checkpoint(name)` and restore them before each spec with
+`ControlPlane.Rollback(name)`, which restarts Etcd from a copy of its data
+and the APIServer on top of it. `Etcd.SaveSnapshot(dir)` and
+`Etcd.RestoreSnapshot(dir)` do the same for a standalone Etcd. To start
+many ControlPlanes, e.g. one per parallel ginkgo node, from the same data, set
+the `DataDirTemplate` of their Etcd to a saved snapshot. It is copied, or
+cloned where the filesystem supports it, into each Etcd's fresh DataDir.
+
+Specs which only create namespaced objects can share a ControlPlane without
+any of this: `ControlPlane.CreateNamespace(prefix)` creates a uniquely named
+namespace, whose `KubeCtl()` works in it. `Namespace.Delete()` deletes its
+contents and finalizes it, as there is no namespace controller to do so.
+
+There are no kubelets either. For the Scheduler, or controllers which need
+Nodes, `ControlPlane.RegisterFakeNodes(count, opts)` registers Nodes with the
+given capacity, labels, taints and conditions, and keeps their Leases and
+conditions fresh like a kubelet would. `FakeNode.SetReady(false)`,
+`StopHeartbeat()` and `Delete()` simulate a failing or disappearing Node. Pods
+scheduled onto FakeNodes are never started.
+
+Users and Authorization
+
+Requests against the APIServer's insecure URL, which the KubeCtl from
+`ControlPlane.KubeCtl()` uses, are neither authenticated nor authorized. To
+test how the API behaves for a specific user, ask the ControlPlane for a
+KubeCtl which authenticates as that user against the secure URL:
+
+	cp := &integration.ControlPlane{
+		APIServer: &integration.APIServer{AuthorizationMode: "RBAC"},
+	}
+	cp.Start()
+	jane := integration.User{Name: "jane", Groups: []string{"devs"}}
+	cp.GrantClusterRoleInNamespace("my-ns", "edit", integration.GroupSubject("devs"))
+	allowed, err := cp.CanI(jane, "create", "deployments.apps", "my-ns")
+	kubeCtl, err := cp.KubeCtlAs(jane)
+
+The APIServer also signs ServiceAccount tokens with a key generated into its
+CertDir. `ControlPlane.KubeCtlForServiceAccount(namespace, name)` returns a
+KubeCtl authenticating with such a token, requested from the TokenRequest API.
+
+To take full control over authentication and authorization decisions, configure
+an AuthWebhook on the APIServer. It serves the APIServer's token authentication
+and authorization webhooks from within the test process, lets the test decide
+on each TokenReview and SubjectAccessReview and records all of them.
+
+To test OpenID Connect authentication, configure an OIDCIssuer on the
+APIServer. It serves the discovery document and signing keys the APIServer
+needs, and mints ID tokens with arbitrary claims, to be used with
+`ControlPlane.KubeCtlWithToken(token)`.
+
+Auditing
+
+Configure an Audit on the APIServer to have it write an audit log into its
+CertDir. `APIServer.AuditEvents()` returns the parsed events, which can be
+matched with `HaveAuditEvent(...)`:
+
+	Eventually(cp.APIServer.AuditEvents).Should(HaveAuditEvent(AuditEventFilter{
+		Verb: "create", Resource: "deployments", User: "jane",
+	}))
+
+Encryption at Rest
+
+Configure an EncryptionConfig on the APIServer to have it encrypt resources
+before storing them. `Etcd.RawValue(...)` returns what actually got stored:
+
+	raw, err := cp.Etcd.RawValue(integration.StorageKey("secrets", "default", "my-secret"))
+	Expect(string(raw)).To(HavePrefix("k8s:enc:aescbc:v1:key1:"))
+
+Admission Webhooks
+
+`ControlPlane.StartAdmissionWebhook(...)` serves an http.Handler as a
+validating or mutating admission webhook, registers it with the APIServer, and
+only returns once the APIServer has started calling it:
+
+	webhook := &integration.AdmissionWebhook{
+		Name:    "pod-policy.example.com",
+		Handler: myHandler,
+		Rules:   []integration.AdmissionRule{{ ... }},
+	}
+	err := cp.StartAdmissionWebhook(webhook)
+	defer webhook.Stop()
+
+Aggregated API Servers
+
+The APIServer is always configured as a front proxy for aggregated APIServers,
+with a client certificate issued by the CA at
+`APIServer.FrontProxyCACertFile()`. `ControlPlane.StartAggregatedAPIServer(...)`
+runs an extension apiserver binary as a managed process, trusting that CA and
+serving with a certificate the APIServer trusts, registers an APIService for
+each of its versions, and only returns once they are all Available:
+
+	wardle := &integration.AggregatedAPIServer{
+		Name:     "wardle-apiserver",
+		Group:    "wardle.example.com",
+		Versions: []string{"v1alpha1"},
+		Args:     []string{ ... },
+	}
+	err := cp.StartAggregatedAPIServer(wardle)
+	defer wardle.Stop()
 
 Binaries
 
-Etcd, APIServer & KubeCtl use the same mechanism to determine which binaries to
-use when they get started.
+Etcd, APIServer, ControllerManager, Scheduler, KubeCtl & Process use the same
+mechanism to determine which binaries to use when they get started. For a
+Process, the binary's name is its `Name`, e.g. `TEST_ASSET_MY_CONTROLLER` and
+`${FRAMEWORK_DIR}/assets/bin/my-controller` for "my-controller".
 
 1. If the component is configured with a `Path` the framework tries to run that
 binary.
@@ -62,19 +238,23 @@ For example:
 	}
 	cp.Start()
 
-2. If the Path field on APIServer, Etcd or KubeCtl is left unset and an
-environment variable named `TEST_ASSET_KUBE_APISERVER`, `TEST_ASSET_ETCD` or
+2. If the Path field on APIServer, Etcd, ControllerManager, Scheduler or
+KubeCtl is left unset and an environment variable named
+`TEST_ASSET_KUBE_APISERVER`, `TEST_ASSET_ETCD`,
+`TEST_ASSET_KUBE_CONTROLLER_MANAGER`, `TEST_ASSET_KUBE_SCHEDULER` or
 `TEST_ASSET_KUBECTL` is set, its value is used as a path to the binary for the
-APIServer, Etcd or KubeCtl.
+APIServer, Etcd, ControllerManager, Scheduler or KubeCtl.
 
 3. If neither the `Path` field, nor the environment variable is set, the
-framework tries to use the binaries `kube-apiserver`, `etcd` or `kubectl` in
-the directory `${FRAMEWORK_DIR}/assets/bin/`.
+framework tries to use the binaries `kube-apiserver`, `etcd`,
+`kube-controller-manager`, `kube-scheduler` or `kubectl` in the directory
+`${FRAMEWORK_DIR}/assets/bin/`.
 
 For convenience this framework ships with
 `${FRAMEWORK_DIR}/scripts/download-binaries.sh` which can be used to download
 pre-compiled versions of the needed binaries and place them in the default
-location (`${FRAMEWORK_DIR}/assets/bin/`).
+location (`${FRAMEWORK_DIR}/assets/bin/`). It downloads kube-controller-manager
+and kube-scheduler in the same version as kube-apiserver.
 
 Arguments for Etcd and APIServer
 
@@ -0,0 +1,162 @@
+package integration
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+// EncryptionConfig configures how the APIServer encrypts resources before it
+// stores them in Etcd. Use Etcd.RawValue() to check how a resource actually
+// ends up in Etcd.
+//
+// For example, to encrypt Secrets with aescbc and a generated key, while
+// still being able to read unencrypted Secrets:
+//
+//	apiServer := &integration.APIServer{
+//		EncryptionConfig: &integration.EncryptionConfig{
+//			Providers: []integration.EncryptionProvider{
+//				{Type: "aescbc"},
+//				{Type: "identity"},
+//			},
+//		},
+//	}
+//
+// To rotate keys, change the Providers (e.g. add a new key in front of the
+// existing ones) and restart the APIServer.
+type EncryptionConfig struct {
+	// Resources are the resources to encrypt.
+	//
+	// If not specified, this defaults to "secrets".
+	Resources []string
+
+	// Providers are the providers used to encrypt and decrypt the resources.
+	// The first provider is used to encrypt, all of them are tried when
+	// decrypting.
+	Providers []EncryptionProvider
+
+	configFile string
+}
+
+// EncryptionProvider is one of the ways the APIServer can encrypt resources.
+type EncryptionProvider struct {
+	// Type is one of "aescbc", "aesgcm", "secretbox" or "identity".
+	Type string
+
+	// Keys are used to encrypt and decrypt, the first key is used to encrypt.
+	// They are ignored by the "identity" provider.
+	//
+	// If not specified, a single key named "key1" is generated the first time
+	// the APIServer is started, and is kept for subsequent starts.
+	Keys []EncryptionKey
+}
+
+// EncryptionKey is a named key used by an EncryptionProvider.
+type EncryptionKey struct {
+	Name   string
+	Secret []byte
+}
+
+// NewEncryptionKey generates a new, random key, suitable for all providers.
+func NewEncryptionKey(name string) (EncryptionKey, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return EncryptionKey{}, err
+	}
+	return EncryptionKey{Name: name, Secret: secret}, nil
+}
+
+// StoragePrefix returns the prefix of the values the APIServer stores in Etcd
+// when it encrypts with this provider and its first key, e.g.
+// "k8s:enc:aescbc:v1:key1:". It is empty for the "identity" provider, which
+// stores resources unencrypted.
+func (p EncryptionProvider) StoragePrefix() string {
+	if p.Type == "identity" || len(p.Keys) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("k8s:enc:%s:v1:%s:", p.Type, p.Keys[0].Name)
+}
+
+// ConfigFile returns the path to the generated encryption configuration. It
+// is empty if the APIServer has not been started yet.
+func (c *EncryptionConfig) ConfigFile() string {
+	return c.configFile
+}
+
+type encryptionConfiguration struct {
+	APIVersion string                     `yaml:"apiVersion"`
+	Kind       string                     `yaml:"kind"`
+	Resources  []encryptionResourceConfig `yaml:"resources"`
+}
+
+type encryptionResourceConfig struct {
+	Resources []string                 `yaml:"resources"`
+	Providers []map[string]interface{} `yaml:"providers"`
+}
+
+type encryptionKeys struct {
+	Keys []encryptionKey `yaml:"keys"`
+}
+
+type encryptionKey struct {
+	Name   string `yaml:"name"`
+	Secret string `yaml:"secret"`
+}
+
+// write generates missing keys and writes the configuration into dir.
+func (c *EncryptionConfig) write(dir string) error {
+	if len(c.Resources) == 0 {
+		c.Resources = []string{"secrets"}
+	}
+	if len(c.Providers) == 0 {
+		return fmt.Errorf("expected at least one encryption provider")
+	}
+
+	providers := []map[string]interface{}{}
+	for i := range c.Providers {
+		provider := &c.Providers[i]
+
+		if provider.Type == "identity" {
+			providers = append(providers, map[string]interface{}{"identity": struct{}{}})
+			continue
+		}
+
+		if len(provider.Keys) == 0 {
+			key, err := NewEncryptionKey("key1")
+			if err != nil {
+				return err
+			}
+			provider.Keys = []EncryptionKey{key}
+		}
+
+		keys := encryptionKeys{}
+		for _, key := range provider.Keys {
+			keys.Keys = append(keys.Keys, encryptionKey{
+				Name:   key.Name,
+				Secret: base64.StdEncoding.EncodeToString(key.Secret),
+			})
+		}
+		providers = append(providers, map[string]interface{}{provider.Type: keys})
+	}
+
+	config := encryptionConfiguration{
+		APIVersion: "apiserver.config.k8s.io/v1",
+		Kind:       "EncryptionConfiguration",
+		Resources: []encryptionResourceConfig{{
+			Resources: c.Resources,
+			Providers: providers,
+		}},
+	}
+
+	content, err := yaml.Marshal(config)
+	if err != nil {
+		return err
+	}
+
+	c.configFile = filepath.Join(dir, "encryption-config.yaml")
+	return ioutil.WriteFile(c.configFile, content, 0600)
+}
@@ -0,0 +1,58 @@
+package integration
+
+import (
+	"encoding/base64"
+	"io/ioutil"
+	"os"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	yaml "gopkg.in/yaml.v2"
+)
+
+var _ = Describe("EncryptionConfig", func() {
+	var dir string
+
+	BeforeEach(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "k8s_test_framework_")
+		Expect(err).NotTo(HaveOccurred())
+	})
+	AfterEach(func() {
+		Expect(os.RemoveAll(dir)).To(Succeed())
+	})
+
+	It("generates keys once and writes the configuration", func() {
+		config := &EncryptionConfig{
+			Providers: []EncryptionProvider{
+				{Type: "aescbc"},
+				{Type: "identity"},
+			},
+		}
+		Expect(config.write(dir)).To(Succeed())
+
+		Expect(config.Resources).To(ConsistOf("secrets"))
+		Expect(config.Providers[0].Keys).To(HaveLen(1))
+		key := config.Providers[0].Keys[0]
+		Expect(key.Name).To(Equal("key1"))
+		Expect(key.Secret).To(HaveLen(32))
+		Expect(config.Providers[0].StoragePrefix()).To(Equal("k8s:enc:aescbc:v1:key1:"))
+		Expect(config.Providers[1].StoragePrefix()).To(BeEmpty())
+
+		content, err := ioutil.ReadFile(config.ConfigFile())
+		Expect(err).NotTo(HaveOccurred())
+		written := map[string]interface{}{}
+		Expect(yaml.Unmarshal(content, &written)).To(Succeed())
+		Expect(written).To(HaveKeyWithValue("kind", "EncryptionConfiguration"))
+		Expect(string(content)).To(ContainSubstring(base64.StdEncoding.EncodeToString(key.Secret)))
+		Expect(string(content)).To(ContainSubstring("identity: {}"))
+
+		By("keeping the generated key when written again")
+		Expect(config.write(dir)).To(Succeed())
+		Expect(config.Providers[0].Keys).To(ConsistOf(key))
+	})
+
+	It("requires at least one provider", func() {
+		Expect((&EncryptionConfig{}).write(dir)).To(MatchError(ContainSubstring("at least one")))
+	})
+})
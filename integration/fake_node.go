@@ -0,0 +1,400 @@
+package integration
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// nodeLeaseNamespace is the namespace kubelets keep their Leases in.
+const nodeLeaseNamespace = "kube-node-lease"
+
+var nodesResource = GroupVersionResource{Version: "v1", Resource: "nodes"}
+
+// FakeNodeOptions configure the Nodes registered by
+// ControlPlane.RegisterFakeNodes.
+type FakeNodeOptions struct {
+	// NamePrefix is the prefix of the Nodes' names, which are followed by a
+	// number, e.g. "fake-node-1".
+	//
+	// If not specified, this defaults to "fake-node".
+	NamePrefix string
+
+	// Capacity is the capacity of each Node, which is also what can be
+	// allocated to Pods, e.g. {"cpu": "4", "memory": "8Gi", "pods": "110"}.
+	//
+	// If not specified, this defaults to the example above.
+	Capacity map[string]string
+
+	// Labels are added to the Nodes' labels, besides
+	// "kubernetes.io/hostname".
+	Labels map[string]string
+
+	// Taints are the Nodes' taints.
+	Taints []Taint
+
+	// Conditions are added to the Nodes' conditions, besides the Ready
+	// condition, e.g. {Type: "MemoryPressure", Status: "False"}.
+	Conditions []NodeCondition
+
+	// HeartbeatInterval is the time between two heartbeats of a Node, which
+	// renew its Lease and the lastHeartbeatTime of its conditions, like a
+	// kubelet does.
+	//
+	// If not specified, this defaults to 10 seconds.
+	HeartbeatInterval time.Duration
+}
+
+// Taint is a taint of a Node, e.g. {Key: "dedicated", Value: "gpu", Effect:
+// "NoSchedule"}.
+type Taint struct {
+	Key    string `json:"key"`
+	Value  string `json:"value,omitempty"`
+	Effect string `json:"effect"`
+}
+
+// NodeCondition is a condition of a Node.
+type NodeCondition struct {
+	Type    string `json:"type"`
+	Status  string `json:"status"`
+	Reason  string `json:"reason,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+// FakeNode is a Node without a kubelet. It keeps its Lease and conditions
+// fresh in the background, like a kubelet would, until it is stopped.
+type FakeNode struct {
+	// Name is the name of the Node.
+	Name string
+
+	client    *RESTClient
+	leaseGVR  *GroupVersionResource
+	interval  time.Duration
+	uid       string
+	stop      chan struct{}
+	done      chan struct{}
+	stopOnce  sync.Once
+	mu        sync.Mutex
+	ready     NodeCondition
+	others    []NodeCondition
+	err       error
+	heartbeat time.Time
+}
+
+// RegisterFakeNodes registers count Nodes, which look like kubelets are
+// running on them, e.g. for the Scheduler, or for controllers which need
+// Nodes. There is no kubelet though: Pods scheduled onto them are never
+// started.
+//
+// The FakeNodes are stopped, but not deleted, when the ControlPlane stops,
+// and by Reset and Rollback, which delete or undo their Nodes. Register them
+// again after those, e.g. in a BeforeEach.
+func (f *ControlPlane) RegisterFakeNodes(count int, opts FakeNodeOptions) ([]*FakeNode, error) {
+	client, err := f.adminRESTClient()
+	if err != nil {
+		return nil, err
+	}
+	if opts.NamePrefix == "" {
+		opts.NamePrefix = "fake-node"
+	}
+	if opts.Capacity == nil {
+		opts.Capacity = map[string]string{"cpu": "4", "memory": "8Gi", "pods": "110"}
+	}
+	if opts.HeartbeatInterval == 0 {
+		opts.HeartbeatInterval = 10 * time.Second
+	}
+
+	leaseGVR, err := nodeLeaseResource(client)
+	if err != nil {
+		return nil, err
+	}
+
+	nodes := []*FakeNode{}
+	for i := 0; i < count; i++ {
+		f.mu.Lock()
+		f.fakeNodeCount++
+		name := fmt.Sprintf("%s-%d", opts.NamePrefix, f.fakeNodeCount)
+		f.mu.Unlock()
+
+		node := &FakeNode{
+			Name:     name,
+			client:   client,
+			leaseGVR: leaseGVR,
+			interval: opts.HeartbeatInterval,
+			ready:    NodeCondition{Type: "Ready", Status: "True", Reason: "KubeletReady", Message: "fake node is ready"},
+			others:   opts.Conditions,
+			stop:     make(chan struct{}),
+			done:     make(chan struct{}),
+		}
+		if err := node.register(opts); err != nil {
+			return nil, err
+		}
+		go node.run()
+		f.mu.Lock()
+		f.fakeNodes = append(f.fakeNodes, node)
+		f.mu.Unlock()
+		nodes = append(nodes, node)
+	}
+	return nodes, nil
+}
+
+// stopFakeNodes stops the heartbeats of all FakeNodes.
+func (f *ControlPlane) stopFakeNodes() {
+	f.mu.Lock()
+	nodes := f.fakeNodes
+	f.fakeNodes = nil
+	f.mu.Unlock()
+
+	for _, node := range nodes {
+		node.StopHeartbeat()
+	}
+}
+
+// nodeLeaseResource returns the resource of Leases, in the preferred version
+// of their group, or nil if the APIServer does not serve them.
+func nodeLeaseResource(client *RESTClient) (*GroupVersionResource, error) {
+	group := struct {
+		PreferredVersion struct {
+			Version string `json:"version"`
+		} `json:"preferredVersion"`
+	}{}
+	err := client.Do("GET", "/apis/coordination.k8s.io", nil, &group)
+	if IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = client.Create(namespacesResource, "", Object{
+		"apiVersion": "v1",
+		"kind":       "Namespace",
+		"metadata":   map[string]interface{}{"name": nodeLeaseNamespace},
+	})
+	if err != nil && !IsAlreadyExists(err) {
+		return nil, err
+	}
+
+	return &GroupVersionResource{Group: "coordination.k8s.io", Version: group.PreferredVersion.Version, Resource: "leases"}, nil
+}
+
+func (n *FakeNode) register(opts FakeNodeOptions) error {
+	labels := map[string]interface{}{"kubernetes.io/hostname": n.Name}
+	for key, value := range opts.Labels {
+		labels[key] = value
+	}
+	capacity := map[string]interface{}{}
+	for resource, quantity := range opts.Capacity {
+		capacity[resource] = quantity
+	}
+	taints := []Taint{}
+	if opts.Taints != nil {
+		taints = opts.Taints
+	}
+
+	now := time.Now()
+	created, err := n.client.Create(nodesResource, "", Object{
+		"apiVersion": "v1",
+		"kind":       "Node",
+		"metadata": map[string]interface{}{
+			"name":   n.Name,
+			"labels": labels,
+		},
+		"spec": map[string]interface{}{
+			"taints": taints,
+		},
+		"status": map[string]interface{}{
+			"capacity":    capacity,
+			"allocatable": capacity,
+			"conditions":  n.conditions(now),
+			"addresses": []interface{}{
+				map[string]interface{}{"type": "Hostname", "address": n.Name},
+			},
+		},
+	})
+	if err != nil {
+		return err
+	}
+	n.uid = created.stringField("metadata.uid")
+	if err := n.renewLease(now); err != nil {
+		return err
+	}
+	n.setHeartbeat(now)
+	return nil
+}
+
+func (n *FakeNode) run() {
+	defer close(n.done)
+	for {
+		select {
+		case <-n.stop:
+			return
+		case <-time.After(n.interval):
+		}
+		n.setErr(n.beat())
+	}
+}
+
+// beat renews the Lease and the conditions of the Node.
+func (n *FakeNode) beat() error {
+	now := time.Now()
+	if err := n.renewLease(now); err != nil {
+		return err
+	}
+	if err := n.updateConditions(now); err != nil {
+		return err
+	}
+	n.setHeartbeat(now)
+	return nil
+}
+
+// conditions returns the Node's conditions, as of a heartbeat at now.
+func (n *FakeNode) conditions(now time.Time) []interface{} {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	conditions := []interface{}{}
+	for _, condition := range append([]NodeCondition{n.ready}, n.others...) {
+		conditions = append(conditions, map[string]interface{}{
+			"type":               condition.Type,
+			"status":             condition.Status,
+			"reason":             condition.Reason,
+			"message":            condition.Message,
+			"lastHeartbeatTime":  now.UTC().Format(time.RFC3339),
+			"lastTransitionTime": now.UTC().Format(time.RFC3339),
+		})
+	}
+	return conditions
+}
+
+func (n *FakeNode) updateConditions(now time.Time) error {
+	current, err := n.client.Get(nodesResource, "", n.Name)
+	if err != nil {
+		return err
+	}
+	conditions := n.conditions(now)
+
+	// Keep the lastTransitionTime of conditions which did not change.
+	existing, _ := current.Field("status.conditions")
+	list, _ := existing.([]interface{})
+	for _, c := range conditions {
+		condition := c.(map[string]interface{})
+		for _, e := range list {
+			old, ok := e.(map[string]interface{})
+			if ok && old["type"] == condition["type"] && old["status"] == condition["status"] && old["lastTransitionTime"] != nil {
+				condition["lastTransitionTime"] = old["lastTransitionTime"]
+			}
+		}
+	}
+
+	patch, err := json.Marshal(map[string]interface{}{
+		"status": map[string]interface{}{"conditions": conditions},
+	})
+	if err != nil {
+		return err
+	}
+	status := GroupVersionResource{Version: "v1", Resource: "nodes/status"}
+	_, err = n.client.Patch(status, "", n.Name, MergePatchType, patch)
+	return err
+}
+
+// renewLease renews the Node's Lease, creating it if needed.
+func (n *FakeNode) renewLease(now time.Time) error {
+	if n.leaseGVR == nil {
+		return nil
+	}
+	renewTime := now.UTC().Format("2006-01-02T15:04:05.000000Z07:00")
+
+	patch := fmt.Sprintf(`{"spec": {"renewTime": %q}}`, renewTime)
+	_, err := n.client.Patch(*n.leaseGVR, nodeLeaseNamespace, n.Name, MergePatchType, []byte(patch))
+	if !IsNotFound(err) {
+		return err
+	}
+
+	_, err = n.client.Create(*n.leaseGVR, nodeLeaseNamespace, Object{
+		"apiVersion": n.leaseGVR.Group + "/" + n.leaseGVR.Version,
+		"kind":       "Lease",
+		"metadata": map[string]interface{}{
+			"name": n.Name,
+			"ownerReferences": []interface{}{map[string]interface{}{
+				"apiVersion": "v1",
+				"kind":       "Node",
+				"name":       n.Name,
+				"uid":        n.uid,
+			}},
+		},
+		"spec": map[string]interface{}{
+			"holderIdentity":       n.Name,
+			"leaseDurationSeconds": 40,
+			"renewTime":            renewTime,
+		},
+	})
+	return err
+}
+
+// SetReady sets the status of the Node's Ready condition to "True" or
+// "False", as a kubelet would report it, and updates the Node's conditions
+// right away.
+func (n *FakeNode) SetReady(ready bool) error {
+	n.mu.Lock()
+	if ready {
+		n.ready = NodeCondition{Type: "Ready", Status: "True", Reason: "KubeletReady", Message: "fake node is ready"}
+	} else {
+		n.ready = NodeCondition{Type: "Ready", Status: "False", Reason: "KubeletNotReady", Message: "fake node is not ready"}
+	}
+	n.mu.Unlock()
+	return n.updateConditions(time.Now())
+}
+
+// StopHeartbeat stops renewing the Node's Lease and conditions, as if its
+// kubelet died. Its conditions are left as they are, until a node lifecycle
+// controller, if any, notices.
+func (n *FakeNode) StopHeartbeat() {
+	n.stopOnce.Do(func() { close(n.stop) })
+	<-n.done
+}
+
+// Delete stops the heartbeats and deletes the Node, and its Lease, as if it
+// disappeared from the cluster.
+func (n *FakeNode) Delete() error {
+	n.StopHeartbeat()
+	if n.leaseGVR != nil {
+		err := n.client.Delete(*n.leaseGVR, nodeLeaseNamespace, n.Name)
+		if err != nil && !IsNotFound(err) {
+			return err
+		}
+	}
+	err := n.client.Delete(nodesResource, "", n.Name)
+	if IsNotFound(err) {
+		return nil
+	}
+	return err
+}
+
+// LastHeartbeat returns the time of the Node's last heartbeat.
+func (n *FakeNode) LastHeartbeat() time.Time {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return n.heartbeat
+}
+
+// Err returns the error the last heartbeat failed with, if any. The Node
+// keeps sending heartbeats nevertheless.
+func (n *FakeNode) Err() error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return n.err
+}
+
+func (n *FakeNode) setHeartbeat(now time.Time) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.heartbeat = now
+}
+
+func (n *FakeNode) setErr(err error) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.err = err
+}
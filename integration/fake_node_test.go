@@ -0,0 +1,137 @@
+package integration
+
+import (
+	"net/http"
+	"net/url"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/ghttp"
+
+	"github.com/kubernetes-sigs/testing_frameworks/integration/internal"
+)
+
+var _ = Describe("FakeNode", func() {
+	It("needs a started ControlPlane", func() {
+		_, err := (&ControlPlane{APIServer: &APIServer{}}).RegisterFakeNodes(1, FakeNodeOptions{})
+		Expect(err).To(MatchError(ContainSubstring("needs to be started")))
+	})
+
+	var (
+		server *recordingServer
+		node   *FakeNode
+	)
+
+	BeforeEach(func() {
+		server = newRecordingServer()
+		node = &FakeNode{
+			Name:     "fake-node-1",
+			client:   server.client,
+			leaseGVR: &GroupVersionResource{Group: "coordination.k8s.io", Version: "v1", Resource: "leases"},
+			interval: time.Hour,
+			ready:    NodeCondition{Type: "Ready", Status: "True", Reason: "KubeletReady"},
+			others:   []NodeCondition{{Type: "MemoryPressure", Status: "False"}},
+			stop:     make(chan struct{}),
+			done:     make(chan struct{}),
+		}
+		server.RouteToHandler("POST", "/api/v1/nodes", server.record(http.StatusOK, `{"metadata": {"name": "fake-node-1", "uid": "node-uid"}}`))
+		server.RouteToHandler("PATCH", "/apis/coordination.k8s.io/v1/namespaces/kube-node-lease/leases/fake-node-1", server.record(http.StatusOK, `{}`))
+		server.RouteToHandler("PATCH", "/api/v1/nodes/fake-node-1/status", server.record(http.StatusOK, `{}`))
+		server.RouteToHandler("DELETE", "/apis/coordination.k8s.io/v1/namespaces/kube-node-lease/leases/fake-node-1", server.record(http.StatusOK, `{}`))
+		server.RouteToHandler("DELETE", "/api/v1/nodes/fake-node-1", server.record(http.StatusOK, `{}`))
+		server.RouteToHandler("GET", "/api/v1/nodes/fake-node-1", ghttp.RespondWith(http.StatusOK, `{
+			"metadata": {"name": "fake-node-1"},
+			"status": {"conditions": [
+				{"type": "Ready", "status": "True", "lastTransitionTime": "2018-06-01T00:00:00Z"},
+				{"type": "MemoryPressure", "status": "True", "lastTransitionTime": "2018-06-01T00:00:00Z"}
+			]}
+		}`))
+	})
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("registers the Node with its status, and creates its Lease", func() {
+		server.RouteToHandler("PATCH", "/apis/coordination.k8s.io/v1/namespaces/kube-node-lease/leases/fake-node-1",
+			ghttp.RespondWith(http.StatusNotFound, `{"kind": "Status", "reason": "NotFound"}`))
+		server.RouteToHandler("POST", "/apis/coordination.k8s.io/v1/namespaces/kube-node-lease/leases", server.record(http.StatusOK, `{}`))
+
+		Expect(node.register(FakeNodeOptions{
+			Capacity: map[string]string{"cpu": "2"},
+			Labels:   map[string]string{"zone": "a"},
+			Taints:   []Taint{{Key: "dedicated", Value: "gpu", Effect: "NoSchedule"}},
+		})).To(Succeed())
+		Expect(node.LastHeartbeat()).NotTo(BeZero())
+
+		Expect(server.recorded()).To(Equal([]string{
+			"POST /api/v1/nodes",
+			"POST /apis/coordination.k8s.io/v1/namespaces/kube-node-lease/leases",
+		}))
+		created := server.recordedBody("POST /api/v1/nodes")
+		Expect(created).To(HaveField("metadata.labels", HaveKeyWithValue("zone", "a")))
+		Expect(created).To(HaveField("metadata.labels", HaveKeyWithValue("kubernetes.io/hostname", "fake-node-1")))
+		Expect(created).To(HaveField("spec.taints", HaveLen(1)))
+		Expect(created).To(HaveField("status.allocatable.cpu", "2"))
+		Expect(created).To(HaveField("status.conditions", HaveLen(2)))
+
+		lease := server.recordedBody("POST /apis/coordination.k8s.io/v1/namespaces/kube-node-lease/leases")
+		Expect(lease).To(HaveField("spec.holderIdentity", "fake-node-1"))
+		Expect(lease).To(HaveField("metadata.ownerReferences", ContainElement(HaveKeyWithValue("uid", "node-uid"))))
+	})
+
+	It("renews its Lease and conditions on every heartbeat", func() {
+		Expect(node.beat()).To(Succeed())
+
+		Expect(server.recorded()).To(Equal([]string{
+			"PATCH /apis/coordination.k8s.io/v1/namespaces/kube-node-lease/leases/fake-node-1",
+			"PATCH /api/v1/nodes/fake-node-1/status",
+		}))
+		conditions, _ := server.recordedBody("PATCH /api/v1/nodes/fake-node-1/status").Field("status.conditions")
+		Expect(conditions).To(ConsistOf(
+			And(HaveKeyWithValue("type", "Ready"), HaveKeyWithValue("lastTransitionTime", "2018-06-01T00:00:00Z")),
+			And(HaveKeyWithValue("type", "MemoryPressure"), Not(HaveKeyWithValue("lastTransitionTime", "2018-06-01T00:00:00Z"))),
+		))
+	})
+
+	It("reports itself as not ready", func() {
+		Expect(node.SetReady(false)).To(Succeed())
+
+		conditions, _ := server.recordedBody("PATCH /api/v1/nodes/fake-node-1/status").Field("status.conditions")
+		Expect(conditions).To(ContainElement(And(
+			HaveKeyWithValue("type", "Ready"),
+			HaveKeyWithValue("status", "False"),
+			HaveKeyWithValue("reason", "KubeletNotReady"),
+		)))
+	})
+
+	It("is stopped by Reset, which deletes its Node", func() {
+		etcd := ghttp.NewServer()
+		defer etcd.Close()
+		etcd.RouteToHandler("POST", "/v3/maintenance/status", ghttp.RespondWith(http.StatusOK, `{}`))
+		etcd.RouteToHandler("POST", "/v3/kv/range", ghttp.RespondWith(http.StatusOK, `{"kvs": []}`))
+		etcdURL, err := url.Parse(etcd.URL())
+		Expect(err).NotTo(HaveOccurred())
+
+		cp := &ControlPlane{
+			Etcd:      &Etcd{client: &internal.EtcdClient{URL: *etcdURL}},
+			fakeNodes: []*FakeNode{node},
+		}
+		go node.run()
+
+		Expect(cp.Reset()).To(Succeed())
+		Expect(node.done).To(BeClosed())
+		Expect(cp.fakeNodes).To(BeEmpty())
+	})
+
+	It("stops its heartbeats, and deletes the Node and its Lease", func() {
+		go node.run()
+
+		Expect(node.Delete()).To(Succeed())
+		Expect(server.recorded()).To(Equal([]string{
+			"DELETE /apis/coordination.k8s.io/v1/namespaces/kube-node-lease/leases/fake-node-1",
+			"DELETE /api/v1/nodes/fake-node-1",
+		}))
+		Expect(node.done).To(BeClosed())
+	})
+})
@@ -0,0 +1,247 @@
+package integration
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// gcObject is an object, and the resource it was listed from.
+type gcObject struct {
+	resource apiResource
+	Object
+}
+
+func (o gcObject) uid() string {
+	return o.stringField("metadata.uid")
+}
+
+func (o gcObject) deleting() bool {
+	_, deleting := o.Field("metadata.deletionTimestamp")
+	return deleting
+}
+
+type ownerReference struct {
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+	Name       string `json:"name"`
+	UID        string `json:"uid"`
+}
+
+func (o gcObject) ownerReferences() []ownerReference {
+	references := []ownerReference{}
+	if value, ok := o.Field("metadata.ownerReferences"); ok {
+		content, _ := json.Marshal(value)
+		json.Unmarshal(content, &references)
+	}
+	return references
+}
+
+func (o gcObject) finalizers() []string {
+	finalizers := []string{}
+	value, _ := o.Field("metadata.finalizers")
+	list, _ := value.([]interface{})
+	for _, finalizer := range list {
+		if s, ok := finalizer.(string); ok {
+			finalizers = append(finalizers, s)
+		}
+	}
+	return finalizers
+}
+
+// collectGarbage deletes objects all of whose owners are gone, and handles
+// owners which wait for their dependents to be deleted or orphaned.
+//
+// The objects of unavailable group versions are left alone, and so are the
+// dependents of owners of those group versions, as they cannot be told to be
+// gone. Owners which are being deleted wait, as their dependents might be
+// among them. The groupDiscoveryFailedError reporting them is returned, once
+// all other objects have been dealt with.
+func (c *Controllers) collectGarbage() error {
+	resources, discoveryErr := c.client.discoverAPIResources("list", "delete")
+	if discoveryErr != nil && !isGroupDiscoveryFailed(discoveryErr) {
+		return discoveryErr
+	}
+
+	objects := []gcObject{}
+	byKind := map[string]apiResource{}
+	for _, resource := range resources {
+		byKind[groupKind(resource.GVR.Group, resource.Kind)] = resource
+
+		list, err := c.client.List(resource.GVR, "")
+		if IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		for _, item := range list.Items() {
+			objects = append(objects, gcObject{resource: resource, Object: item})
+		}
+	}
+
+	existing := map[string]bool{}
+	dependents := map[string][]gcObject{}
+	for _, object := range objects {
+		existing[object.uid()] = true
+		for _, reference := range object.ownerReferences() {
+			dependents[reference.UID] = append(dependents[reference.UID], object)
+		}
+	}
+
+	for _, object := range objects {
+		if object.deleting() {
+			if discoveryErr != nil {
+				continue
+			}
+			if err := c.finishDeletion(object, dependents[object.uid()]); err != nil {
+				return err
+			}
+			continue
+		}
+
+		references := object.ownerReferences()
+		if len(references) == 0 {
+			continue
+		}
+		orphaned := true
+		for _, reference := range references {
+			gone, err := c.ownerGone(reference, object, existing, byKind)
+			if err != nil {
+				return err
+			}
+			if !gone {
+				orphaned = false
+				break
+			}
+		}
+		if orphaned {
+			if err := c.deleteInBackground(object); err != nil {
+				return err
+			}
+		}
+	}
+	return discoveryErr
+}
+
+// ownerGone checks whether the owner the dependent references is gone. The
+// owner is looked up again, as it might have been created after its resource
+// was listed.
+func (c *Controllers) ownerGone(reference ownerReference, dependent gcObject, existing map[string]bool, byKind map[string]apiResource) (bool, error) {
+	if existing[reference.UID] {
+		return false, nil
+	}
+
+	group := ""
+	if i := strings.Index(reference.APIVersion, "/"); i >= 0 {
+		group = reference.APIVersion[:i]
+	}
+	resource, ok := byKind[groupKind(group, reference.Kind)]
+	if !ok {
+		// Without knowing the owner's resource, we cannot tell.
+		return false, nil
+	}
+
+	namespace := ""
+	if resource.Namespaced {
+		namespace = dependent.Namespace()
+	}
+	owner, err := c.client.Get(resource.GVR, namespace, reference.Name)
+	if IsNotFound(err) {
+		return true, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return owner.stringField("metadata.uid") != reference.UID, nil
+}
+
+// finishDeletion handles the finalizers the garbage collector is responsible
+// for, on an owner which is being deleted: "foregroundDeletion" is removed
+// once all dependents are gone, and "orphan" once the owner has been removed
+// from the dependents' ownerReferences.
+func (c *Controllers) finishDeletion(owner gcObject, dependents []gcObject) error {
+	finalizers := owner.finalizers()
+
+	if containsString(finalizers, "foregroundDeletion") {
+		if len(dependents) > 0 {
+			for _, dependent := range dependents {
+				if dependent.deleting() {
+					continue
+				}
+				if err := c.deleteInBackground(dependent); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+		return c.removeFinalizer(owner, "foregroundDeletion")
+	}
+
+	if containsString(finalizers, "orphan") {
+		for _, dependent := range dependents {
+			if err := c.removeOwnerReference(dependent, owner.uid()); err != nil {
+				return err
+			}
+		}
+		return c.removeFinalizer(owner, "orphan")
+	}
+	return nil
+}
+
+func (c *Controllers) deleteInBackground(object gcObject) error {
+	path := c.client.ResourcePath(object.resource.GVR, object.Namespace(), object.Name())
+	err := c.client.Do("DELETE", path, Object{
+		"apiVersion":        "v1",
+		"kind":              "DeleteOptions",
+		"propagationPolicy": "Background",
+	}, nil)
+	if IsNotFound(err) {
+		return nil
+	}
+	return err
+}
+
+func (c *Controllers) removeFinalizer(object gcObject, finalizer string) error {
+	finalizers := []string{}
+	for _, f := range object.finalizers() {
+		if f != finalizer {
+			finalizers = append(finalizers, f)
+		}
+	}
+	return c.patchMetadata(object, map[string]interface{}{"finalizers": finalizers})
+}
+
+func (c *Controllers) removeOwnerReference(object gcObject, uid string) error {
+	// The references are kept as they are, not just the fields we know of.
+	value, _ := object.Field("metadata.ownerReferences")
+	references, _ := value.([]interface{})
+	kept := []interface{}{}
+	for _, reference := range references {
+		if r, ok := reference.(map[string]interface{}); ok && r["uid"] != uid {
+			kept = append(kept, r)
+		}
+	}
+	if len(kept) == len(references) {
+		return nil
+	}
+	return c.patchMetadata(object, map[string]interface{}{"ownerReferences": kept})
+}
+
+// patchMetadata patches the object's metadata, unless the object has been
+// changed since it was listed, in which case it is retried on the next run.
+func (c *Controllers) patchMetadata(object gcObject, metadata map[string]interface{}) error {
+	metadata["resourceVersion"] = object.stringField("metadata.resourceVersion")
+	patch, err := json.Marshal(map[string]interface{}{"metadata": metadata})
+	if err != nil {
+		return err
+	}
+	_, err = c.client.Patch(object.resource.GVR, object.Namespace(), object.Name(), MergePatchType, patch)
+	if IsNotFound(err) || IsConflict(err) {
+		return nil
+	}
+	return err
+}
+
+func groupKind(group, kind string) string {
+	return kind + "." + group
+}
@@ -3,6 +3,7 @@ package internal
 import (
 	"fmt"
 	"net"
+	"net/url"
 )
 
 // AddressManager allocates a new address (interface & port) a process
@@ -51,3 +52,17 @@ func (d *AddressManager) Host() (string, error) {
 	}
 	return d.host, nil
 }
+
+// NewLocalURL returns a URL with the given scheme, pointing to a free port on
+// localhost.
+func NewLocalURL(scheme string) (*url.URL, error) {
+	am := &AddressManager{}
+	port, host, err := am.Initialize()
+	if err != nil {
+		return nil, err
+	}
+	return &url.URL{
+		Scheme: scheme,
+		Host:   fmt.Sprintf("%s:%d", host, port),
+	}, nil
+}
@@ -0,0 +1,35 @@
+package internal
+
+// File names of the certificates of an aggregated APIServer, relative to its
+// CertDir.
+const (
+	AggregatedAPIServerServingCertFile     = "aggregated-apiserver.crt"
+	AggregatedAPIServerServingKeyFile      = "aggregated-apiserver.key"
+	AggregatedAPIServerClientCAFile        = "client-ca.crt"
+	AggregatedAPIServerRequestHeaderCAFile = "requestheader-ca.crt"
+)
+
+var AggregatedAPIServerDefaultArgs = []string{
+	"--secure-port={{ if .SecureURL }}{{ .SecureURL.Port }}{{ end }}",
+	"--bind-address={{ if .SecureURL }}{{ .SecureURL.Hostname }}{{ end }}",
+	"--cert-dir={{ .CertDir }}",
+	"--tls-cert-file={{ .CertDir }}/" + AggregatedAPIServerServingCertFile,
+	"--tls-private-key-file={{ .CertDir }}/" + AggregatedAPIServerServingKeyFile,
+	"--client-ca-file={{ .CertDir }}/" + AggregatedAPIServerClientCAFile,
+	"--requestheader-client-ca-file={{ .CertDir }}/" + AggregatedAPIServerRequestHeaderCAFile,
+	"--requestheader-allowed-names=" + FrontProxyClientName,
+	"--requestheader-username-headers=X-Remote-User",
+	"--requestheader-group-headers=X-Remote-Group",
+	"--requestheader-extra-headers-prefix=X-Remote-Extra-",
+	"--kubeconfig={{ .KubeConfigFile }}",
+	"--authentication-kubeconfig={{ .KubeConfigFile }}",
+	"--authorization-kubeconfig={{ .KubeConfigFile }}",
+}
+
+func DoAggregatedAPIServerArgDefaulting(args []string) []string {
+	if len(args) != 0 {
+		return args
+	}
+
+	return AggregatedAPIServerDefaultArgs
+}
@@ -1,11 +1,66 @@
 package internal
 
+import (
+	"crypto/rsa"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+// File names of the certificates the APIServer uses for secure serving and
+// client authentication, relative to the APIServer's CertDir.
+const (
+	APIServerCACertFile      = "apiserver-ca.crt"
+	APIServerServingCertFile = "apiserver.crt"
+	APIServerServingKeyFile  = "apiserver.key"
+
+	APIServerServiceAccountKeyFile       = "sa.key"
+	APIServerServiceAccountPublicKeyFile = "sa.pub"
+
+	APIServerFrontProxyCACertFile     = "front-proxy-ca.crt"
+	APIServerFrontProxyClientCertFile = "front-proxy-client.crt"
+	APIServerFrontProxyClientKeyFile  = "front-proxy-client.key"
+)
+
+// FrontProxyClientName is the name in the client certificate the APIServer
+// authenticates with against aggregated APIServers, which they need to allow.
+const FrontProxyClientName = "front-proxy-client"
+
 var APIServerDefaultArgs = []string{
 	"--etcd-servers={{ if .EtcdURL }}{{ .EtcdURL.String }}{{ end }}",
 	"--cert-dir={{ .CertDir }}",
 	"--insecure-port={{ if .URL }}{{ .URL.Port }}{{ end }}",
 	"--insecure-bind-address={{ if .URL }}{{ .URL.Hostname }}{{ end }}",
-	"--secure-port=0",
+	"--secure-port={{ if .SecureURL }}{{ .SecureURL.Port }}{{ end }}",
+	"--bind-address={{ if .SecureURL }}{{ .SecureURL.Hostname }}{{ end }}",
+	"--tls-cert-file={{ .CertDir }}/" + APIServerServingCertFile,
+	"--tls-private-key-file={{ .CertDir }}/" + APIServerServingKeyFile,
+	"--client-ca-file={{ .CertDir }}/" + APIServerCACertFile,
+	"--authorization-mode={{ .AuthorizationMode }}",
+	"--service-account-issuer={{ .ServiceAccountIssuer }}",
+	"--service-account-key-file={{ .CertDir }}/" + APIServerServiceAccountPublicKeyFile,
+	"--service-account-signing-key-file={{ .CertDir }}/" + APIServerServiceAccountKeyFile,
+	"{{ if .AuthWebhook }}--authentication-token-webhook-config-file={{ .AuthWebhook.AuthenticationConfigFile }}{{ end }}",
+	"{{ if .AuthWebhook }}--authentication-token-webhook-cache-ttl=0s{{ end }}",
+	"{{ if .AuthWebhook }}--authorization-webhook-config-file={{ .AuthWebhook.AuthorizationConfigFile }}{{ end }}",
+	"{{ if .AuthWebhook }}--authorization-webhook-cache-authorized-ttl=0s{{ end }}",
+	"{{ if .AuthWebhook }}--authorization-webhook-cache-unauthorized-ttl=0s{{ end }}",
+	"{{ if .Audit }}--audit-policy-file={{ .Audit.PolicyFile }}{{ end }}",
+	"{{ if .Audit }}--audit-log-path={{ .Audit.LogFile }}{{ end }}",
+	"{{ if .EncryptionConfig }}--encryption-provider-config={{ .EncryptionConfig.ConfigFile }}{{ end }}",
+	"{{ if .OIDCIssuer }}--oidc-issuer-url={{ .OIDCIssuer.URL }}{{ end }}",
+	"{{ if .OIDCIssuer }}--oidc-ca-file={{ .OIDCIssuer.CAFile }}{{ end }}",
+	"{{ if .OIDCIssuer }}--oidc-client-id={{ .OIDCIssuer.ClientID }}{{ end }}",
+	"{{ if .OIDCIssuer }}--oidc-username-claim={{ .OIDCIssuer.UsernameClaim }}{{ end }}",
+	"{{ if .OIDCIssuer }}--oidc-username-prefix={{ .OIDCIssuer.UsernamePrefix }}{{ end }}",
+	"{{ if .OIDCIssuer }}--oidc-groups-claim={{ .OIDCIssuer.GroupsClaim }}{{ end }}",
+	"--requestheader-client-ca-file={{ .CertDir }}/" + APIServerFrontProxyCACertFile,
+	"--requestheader-allowed-names=" + FrontProxyClientName,
+	"--requestheader-username-headers=X-Remote-User",
+	"--requestheader-group-headers=X-Remote-Group",
+	"--requestheader-extra-headers-prefix=X-Remote-Extra-",
+	"--proxy-client-cert-file={{ .CertDir }}/" + APIServerFrontProxyClientCertFile,
+	"--proxy-client-key-file={{ .CertDir }}/" + APIServerFrontProxyClientKeyFile,
 }
 
 func DoAPIServerArgDefaulting(args []string) []string {
@@ -15,3 +70,104 @@ func DoAPIServerArgDefaulting(args []string) []string {
 
 	return APIServerDefaultArgs
 }
+
+// WriteAPIServerCerts writes the certificate of the CA, and a serving
+// certificate for the given hosts issued by that CA, into dir.
+//
+// The same CA is used to verify client certificates, so every client
+// certificate issued by the CA can be used to authenticate against the
+// APIServer.
+func WriteAPIServerCerts(dir string, ca *TinyCA, hosts ...string) error {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return err
+	}
+
+	servingCerts, err := ca.NewServingCert(append(hosts, "localhost")...)
+	if err != nil {
+		return err
+	}
+	cert, key, err := servingCerts.AsBytes()
+	if err != nil {
+		return err
+	}
+
+	files := map[string][]byte{
+		APIServerCACertFile:      ca.CertBytes(),
+		APIServerServingCertFile: cert,
+		APIServerServingKeyFile:  key,
+	}
+	return writeFiles(dir, files)
+}
+
+// WriteFrontProxyCerts writes the certificate of the front proxy CA, and a
+// client certificate issued by it, which the APIServer authenticates with
+// when proxying requests to aggregated APIServers, into dir.
+func WriteFrontProxyCerts(dir string, ca *TinyCA) error {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return err
+	}
+
+	clientCert, err := ca.NewClientCert(FrontProxyClientName)
+	if err != nil {
+		return err
+	}
+	cert, key, err := clientCert.AsBytes()
+	if err != nil {
+		return err
+	}
+
+	return writeFiles(dir, map[string][]byte{
+		APIServerFrontProxyCACertFile:     ca.CertBytes(),
+		APIServerFrontProxyClientCertFile: cert,
+		APIServerFrontProxyClientKeyFile:  key,
+	})
+}
+
+// WriteServiceAccountKeys writes the key the APIServer uses to sign
+// ServiceAccount tokens, and its public part to verify them, into dir.
+func WriteServiceAccountKeys(dir string, key *rsa.PrivateKey) error {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return err
+	}
+
+	private, public, err := RSAKeyAsBytes(key)
+	if err != nil {
+		return err
+	}
+
+	return writeFiles(dir, map[string][]byte{
+		APIServerServiceAccountKeyFile:       private,
+		APIServerServiceAccountPublicKeyFile: public,
+	})
+}
+
+// WriteServingCert writes a serving certificate for the given hosts issued by
+// the CA, and its key, into dir.
+func WriteServingCert(dir string, ca *TinyCA, certFile, keyFile string, hosts ...string) error {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return err
+	}
+
+	servingCert, err := ca.NewServingCert(append(hosts, "localhost")...)
+	if err != nil {
+		return err
+	}
+	cert, key, err := servingCert.AsBytes()
+	if err != nil {
+		return err
+	}
+
+	return writeFiles(dir, map[string][]byte{
+		certFile: cert,
+		keyFile:  key,
+	})
+}
+
+func writeFiles(dir string, files map[string][]byte) error {
+	for name, content := range files {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), content, 0600); err != nil {
+			return err
+		}
+	}
+	return nil
+}
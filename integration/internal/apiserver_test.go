@@ -1,6 +1,12 @@
 package internal_test
 
 import (
+	"crypto/tls"
+	"crypto/x509"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
 	. "github.com/kubernetes-sigs/testing_frameworks/integration/internal"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
@@ -21,3 +27,33 @@ var _ = Describe("Apiserver", func() {
 		}))
 	})
 })
+
+var _ = Describe("WriteFrontProxyCerts", func() {
+	It("writes the front proxy CA, and a client certificate issued by it", func() {
+		dir, err := ioutil.TempDir("", "front_proxy_test_")
+		Expect(err).NotTo(HaveOccurred())
+		defer os.RemoveAll(dir)
+		ca, err := NewTinyCA("front-proxy-ca")
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(WriteFrontProxyCerts(dir, ca)).To(Succeed())
+
+		caCert, err := ioutil.ReadFile(filepath.Join(dir, APIServerFrontProxyCACertFile))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(caCert).To(Equal(ca.CertBytes()))
+
+		pair, err := tls.LoadX509KeyPair(
+			filepath.Join(dir, APIServerFrontProxyClientCertFile),
+			filepath.Join(dir, APIServerFrontProxyClientKeyFile),
+		)
+		Expect(err).NotTo(HaveOccurred())
+		cert, err := x509.ParseCertificate(pair.Certificate[0])
+		Expect(err).NotTo(HaveOccurred())
+		Expect(cert.Subject.CommonName).To(Equal(FrontProxyClientName))
+
+		roots := x509.NewCertPool()
+		roots.AppendCertsFromPEM(caCert)
+		_, err = cert.Verify(x509.VerifyOptions{Roots: roots, KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth}})
+		Expect(err).NotTo(HaveOccurred())
+	})
+})
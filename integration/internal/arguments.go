@@ -5,6 +5,9 @@ import (
 	"html/template"
 )
 
+// RenderTemplates renders each of the argTemplates with data. Arguments which
+// render to an empty string are dropped, so that a template can add an
+// argument conditionally, e.g. "{{ if .Field }}--flag={{ .Field }}{{ end }}".
 func RenderTemplates(argTemplates []string, data interface{}) (args []string, err error) {
 	var t *template.Template
 
@@ -21,6 +24,9 @@ func RenderTemplates(argTemplates []string, data interface{}) (args []string, er
 			args = nil
 			return
 		}
+		if buf.Len() == 0 {
+			continue
+		}
 		args = append(args, buf.String())
 	}
 
@@ -56,6 +56,28 @@ var _ = Describe("Arguments", func() {
 		}))
 	})
 
+	It("drops arguments which render empty", func() {
+		templates := []string{
+			"--always",
+			"{{ if .SomeString }}--some-string={{ .SomeString }}{{ end }}",
+			"{{ if .EmptyString }}--empty-string={{ .EmptyString }}{{ end }}",
+		}
+		data := struct {
+			SomeString  string
+			EmptyString string
+		}{
+			"some",
+			"",
+		}
+
+		out, err := RenderTemplates(templates, data)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(out).To(BeEquivalentTo([]string{
+			"--always",
+			"--some-string=some",
+		}))
+	})
+
 	It("has no access to unexported fields", func() {
 		templates := []string{
 			"this is just a string",
@@ -0,0 +1,13 @@
+//go:build !linux || !(386 || amd64 || arm || arm64 || loong64 || riscv64 || s390x)
+// +build !linux !386,!amd64,!arm,!arm64,!loong64,!riscv64,!s390x
+
+package internal
+
+import (
+	"fmt"
+	"os"
+)
+
+func cloneFile(dst, src *os.File) error {
+	return fmt.Errorf("cloning files is not supported on this platform")
+}
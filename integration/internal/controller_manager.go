@@ -0,0 +1,31 @@
+package internal
+
+// File names of the serving certificate of the ControllerManager, relative
+// to its CertDir.
+const (
+	ControllerManagerServingCertFile = "controller-manager.crt"
+	ControllerManagerServingKeyFile  = "controller-manager.key"
+)
+
+var ControllerManagerDefaultArgs = []string{
+	"--kubeconfig={{ .KubeConfigFile }}",
+	"--authentication-kubeconfig={{ .KubeConfigFile }}",
+	"--authorization-kubeconfig={{ .KubeConfigFile }}",
+	"--port=0",
+	"--secure-port={{ if .SecureURL }}{{ .SecureURL.Port }}{{ end }}",
+	"--bind-address={{ if .SecureURL }}{{ .SecureURL.Hostname }}{{ end }}",
+	"--cert-dir={{ .CertDir }}",
+	"--tls-cert-file={{ .CertDir }}/" + ControllerManagerServingCertFile,
+	"--tls-private-key-file={{ .CertDir }}/" + ControllerManagerServingKeyFile,
+	"--service-account-private-key-file={{ .ServiceAccountKeyFile }}",
+	"--root-ca-file={{ .RootCAFile }}",
+	"--leader-elect=false",
+}
+
+func DoControllerManagerArgDefaulting(args []string) []string {
+	if len(args) != 0 {
+		return args
+	}
+
+	return ControllerManagerDefaultArgs
+}
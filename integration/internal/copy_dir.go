@@ -0,0 +1,87 @@
+package internal
+
+import (
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+// CopyDir copies the directory src, with all its files, directories and
+// symlinks, to dst, which must not exist yet. Permissions are kept. Where the
+// filesystem supports it, files are cloned copy-on-write rather than copied,
+// which is a lot faster for big files.
+func CopyDir(src, dst string) error {
+	return filepath.Walk(src, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+
+		switch {
+		case info.IsDir():
+			if err := os.Mkdir(target, info.Mode().Perm()); err != nil {
+				return err
+			}
+			// Mkdir is subject to the umask, the copy should not be.
+			return os.Chmod(target, info.Mode().Perm())
+		case info.Mode()&os.ModeSymlink != 0:
+			link, err := os.Readlink(path)
+			if err != nil {
+				return err
+			}
+			return os.Symlink(link, target)
+		default:
+			return copyFile(path, target, info.Mode().Perm())
+		}
+	})
+}
+
+func copyFile(src, dst string, perm os.FileMode) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_EXCL, perm)
+	if err != nil {
+		return err
+	}
+	if err := cloneFile(out, in); err != nil {
+		if _, err := io.Copy(out, in); err != nil {
+			out.Close()
+			return err
+		}
+	}
+	return out.Close()
+}
+
+// SeedDir fills dir with a copy of the template directory, unless dir already
+// contains something, which is then kept as it is. It returns whether dir has
+// been seeded.
+func SeedDir(dir, template string) (bool, error) {
+	if _, err := os.Stat(template); err != nil {
+		return false, err
+	}
+
+	entries, err := ioutil.ReadDir(dir)
+	if err != nil && !os.IsNotExist(err) {
+		return false, err
+	}
+	if len(entries) > 0 {
+		return false, nil
+	}
+
+	if err := os.RemoveAll(dir); err != nil {
+		return false, err
+	}
+	if err := CopyDir(template, dir); err != nil {
+		return false, err
+	}
+	return true, nil
+}
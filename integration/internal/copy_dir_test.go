@@ -0,0 +1,98 @@
+package internal_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	. "github.com/kubernetes-sigs/testing_frameworks/integration/internal"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("CopyDir", func() {
+	var tmp string
+
+	BeforeEach(func() {
+		var err error
+		tmp, err = ioutil.TempDir("", "k8s_test_framework_")
+		Expect(err).NotTo(HaveOccurred())
+	})
+	AfterEach(func() {
+		Expect(os.RemoveAll(tmp)).To(Succeed())
+	})
+
+	It("copies files, directories and symlinks with their permissions", func() {
+		src := filepath.Join(tmp, "src")
+		Expect(os.MkdirAll(filepath.Join(src, "member", "wal"), 0700)).To(Succeed())
+		Expect(ioutil.WriteFile(filepath.Join(src, "member", "wal", "0.wal"), []byte("some data"), 0600)).To(Succeed())
+		Expect(ioutil.WriteFile(filepath.Join(src, "script"), []byte("#!/bin/sh"), 0755)).To(Succeed())
+		Expect(os.Symlink("script", filepath.Join(src, "link"))).To(Succeed())
+
+		dst := filepath.Join(tmp, "dst")
+		Expect(CopyDir(src, dst)).To(Succeed())
+
+		Expect(ioutil.ReadFile(filepath.Join(dst, "member", "wal", "0.wal"))).To(Equal([]byte("some data")))
+
+		info, err := os.Stat(filepath.Join(dst, "member"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(info.Mode().Perm()).To(Equal(os.FileMode(0700)))
+		info, err = os.Stat(filepath.Join(dst, "script"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(info.Mode().Perm()).To(Equal(os.FileMode(0755)))
+
+		Expect(os.Readlink(filepath.Join(dst, "link"))).To(Equal("script"))
+	})
+
+	It("does not overwrite an existing destination", func() {
+		src := filepath.Join(tmp, "src")
+		Expect(os.Mkdir(src, 0700)).To(Succeed())
+
+		Expect(CopyDir(src, tmp)).NotTo(Succeed())
+	})
+})
+
+var _ = Describe("SeedDir", func() {
+	var tmp, template string
+
+	BeforeEach(func() {
+		var err error
+		tmp, err = ioutil.TempDir("", "k8s_test_framework_")
+		Expect(err).NotTo(HaveOccurred())
+		template = filepath.Join(tmp, "template")
+		Expect(os.Mkdir(template, 0700)).To(Succeed())
+		Expect(ioutil.WriteFile(filepath.Join(template, "data"), []byte("golden"), 0600)).To(Succeed())
+	})
+	AfterEach(func() {
+		Expect(os.RemoveAll(tmp)).To(Succeed())
+	})
+
+	It("seeds an empty directory", func() {
+		dir := filepath.Join(tmp, "empty")
+		Expect(os.Mkdir(dir, 0700)).To(Succeed())
+
+		Expect(SeedDir(dir, template)).To(BeTrue())
+		Expect(ioutil.ReadFile(filepath.Join(dir, "data"))).To(Equal([]byte("golden")))
+	})
+
+	It("seeds a directory which does not exist yet", func() {
+		dir := filepath.Join(tmp, "new")
+
+		Expect(SeedDir(dir, template)).To(BeTrue())
+		Expect(ioutil.ReadFile(filepath.Join(dir, "data"))).To(Equal([]byte("golden")))
+	})
+
+	It("keeps a directory which already contains data", func() {
+		dir := filepath.Join(tmp, "used")
+		Expect(os.Mkdir(dir, 0700)).To(Succeed())
+		Expect(ioutil.WriteFile(filepath.Join(dir, "data"), []byte("changed"), 0600)).To(Succeed())
+
+		Expect(SeedDir(dir, template)).To(BeFalse())
+		Expect(ioutil.ReadFile(filepath.Join(dir, "data"))).To(Equal([]byte("changed")))
+	})
+
+	It("fails if the template does not exist", func() {
+		_, err := SeedDir(filepath.Join(tmp, "dir"), filepath.Join(tmp, "missing"))
+		Expect(err).To(HaveOccurred())
+	})
+})
@@ -0,0 +1,161 @@
+package internal
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"sync"
+)
+
+// etcdGatewayPrefixes are the paths different etcd versions serve the JSON
+// gateway of their v3 API on, newest first.
+var etcdGatewayPrefixes = []string{"/v3", "/v3beta", "/v3alpha"}
+
+// EtcdClient talks to etcd's v3 API via its JSON gateway, so we don't need to
+// pull in the etcd client and gRPC.
+type EtcdClient struct {
+	URL url.URL
+
+	once       sync.Once
+	prefix     string
+	prefixErr  error
+	httpClient http.Client
+}
+
+// EtcdKeyValue is a key and its value stored in etcd.
+type EtcdKeyValue struct {
+	Key   []byte `json:"key"`
+	Value []byte `json:"value"`
+}
+
+type etcdRangeRequest struct {
+	Key      []byte `json:"key"`
+	RangeEnd []byte `json:"range_end,omitempty"`
+	KeysOnly bool   `json:"keys_only,omitempty"`
+}
+
+type etcdRangeResponse struct {
+	KVs []EtcdKeyValue `json:"kvs"`
+}
+
+type etcdDeleteRangeRequest struct {
+	Key []byte `json:"key"`
+}
+
+type etcdDeleteRangeResponse struct {
+	Header struct {
+		// The gateway encodes int64 values as strings.
+		Revision int64 `json:"revision,string"`
+	} `json:"header"`
+}
+
+// Get returns the value stored for key, and whether the key exists at all.
+func (c *EtcdClient) Get(key string) ([]byte, bool, error) {
+	res := etcdRangeResponse{}
+	if err := c.post("/kv/range", etcdRangeRequest{Key: []byte(key)}, &res); err != nil {
+		return nil, false, err
+	}
+	if len(res.KVs) == 0 {
+		return nil, false, nil
+	}
+	return res.KVs[0].Value, true, nil
+}
+
+// Keys returns all keys starting with prefix, in lexical order.
+func (c *EtcdClient) Keys(prefix string) ([]string, error) {
+	req := etcdRangeRequest{
+		Key:      []byte(prefix),
+		RangeEnd: prefixRangeEnd([]byte(prefix)),
+		KeysOnly: true,
+	}
+	res := etcdRangeResponse{}
+	if err := c.post("/kv/range", req, &res); err != nil {
+		return nil, err
+	}
+
+	keys := make([]string, len(res.KVs))
+	for i, kv := range res.KVs {
+		keys[i] = string(kv.Key)
+	}
+	return keys, nil
+}
+
+// Delete deletes the key, and returns the revision of the store after the
+// deletion.
+func (c *EtcdClient) Delete(key string) (int64, error) {
+	res := etcdDeleteRangeResponse{}
+	if err := c.post("/kv/deleterange", etcdDeleteRangeRequest{Key: []byte(key)}, &res); err != nil {
+		return 0, err
+	}
+	return res.Header.Revision, nil
+}
+
+// prefixRangeEnd returns the end of the range of keys starting with prefix,
+// which is the prefix with its last byte incremented.
+func prefixRangeEnd(prefix []byte) []byte {
+	end := append([]byte{}, prefix...)
+	for i := len(end) - 1; i >= 0; i-- {
+		if end[i] < 0xff {
+			end[i]++
+			return end[:i+1]
+		}
+	}
+	// The prefix only consists of 0xff bytes, so the range ends with the
+	// keyspace.
+	return []byte{0}
+}
+
+// post sends the request to the gateway endpoint and decodes the response.
+func (c *EtcdClient) post(path string, req, res interface{}) error {
+	c.once.Do(c.discoverPrefix)
+	if c.prefixErr != nil {
+		return c.prefixErr
+	}
+
+	status, body, err := c.doPost(c.prefix+path, req)
+	if err != nil {
+		return err
+	}
+	if status != http.StatusOK {
+		return fmt.Errorf("etcd responded to %s with %d: %s", path, status, body)
+	}
+	return json.Unmarshal(body, res)
+}
+
+// discoverPrefix finds the path the gateway is served on, by checking which
+// one does not respond with 404.
+func (c *EtcdClient) discoverPrefix() {
+	for _, prefix := range etcdGatewayPrefixes {
+		status, _, err := c.doPost(prefix+"/maintenance/status", struct{}{})
+		if err != nil {
+			c.prefixErr = err
+			return
+		}
+		if status != http.StatusNotFound {
+			c.prefix = prefix
+			return
+		}
+	}
+	c.prefixErr = fmt.Errorf("could not find etcd's JSON gateway on %s", c.URL.String())
+}
+
+func (c *EtcdClient) doPost(path string, req interface{}) (int, []byte, error) {
+	reqBody, err := json.Marshal(req)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	u := c.URL
+	u.Path = path
+	res, err := c.httpClient.Post(u.String(), "application/json", bytes.NewReader(reqBody))
+	if err != nil {
+		return 0, nil, err
+	}
+	defer res.Body.Close()
+
+	body, err := ioutil.ReadAll(res.Body)
+	return res.StatusCode, body, err
+}
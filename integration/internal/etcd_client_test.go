@@ -0,0 +1,110 @@
+package internal_test
+
+import (
+	"encoding/json"
+	"net/http"
+
+	. "github.com/kubernetes-sigs/testing_frameworks/integration/internal"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/ghttp"
+)
+
+var _ = Describe("EtcdClient", func() {
+	var (
+		server *ghttp.Server
+		client *EtcdClient
+	)
+
+	BeforeEach(func() {
+		server = ghttp.NewServer()
+		client = &EtcdClient{URL: getServerURL(server)}
+
+		server.RouteToHandler("POST", "/v3/maintenance/status", ghttp.RespondWith(http.StatusNotFound, ""))
+		server.RouteToHandler("POST", "/v3beta/maintenance/status", ghttp.RespondWith(http.StatusOK, "{}"))
+	})
+	AfterEach(func() {
+		server.Close()
+	})
+
+	Describe("Get", func() {
+		It("uses the gateway prefix the server supports", func() {
+			server.RouteToHandler("POST", "/v3beta/kv/range", ghttp.CombineHandlers(
+				ghttp.VerifyJSON(`{"key": "L3JlZ2lzdHJ5L3NlY3JldHMvZGVmYXVsdC9mb28="}`),
+				ghttp.RespondWithJSONEncoded(http.StatusOK, map[string]interface{}{
+					"kvs": []map[string]interface{}{{
+						"key":   []byte("/registry/secrets/default/foo"),
+						"value": []byte("k8s:enc:aescbc:v1:key1:garbage"),
+					}},
+				}),
+			))
+
+			value, found, err := client.Get("/registry/secrets/default/foo")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(found).To(BeTrue())
+			Expect(string(value)).To(Equal("k8s:enc:aescbc:v1:key1:garbage"))
+
+			_, _, err = client.Get("/registry/secrets/default/foo")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(server.ReceivedRequests()).To(HaveLen(4))
+		})
+
+		It("reports missing keys", func() {
+			server.RouteToHandler("POST", "/v3beta/kv/range", ghttp.RespondWith(http.StatusOK, "{}"))
+
+			_, found, err := client.Get("/registry/secrets/default/missing")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(found).To(BeFalse())
+		})
+
+		It("surfaces errors returned by etcd", func() {
+			body, _ := json.Marshal(map[string]string{"error": "boom"})
+			server.RouteToHandler("POST", "/v3beta/kv/range", ghttp.RespondWith(http.StatusInternalServerError, body))
+
+			_, _, err := client.Get("/some/key")
+			Expect(err).To(MatchError(ContainSubstring("boom")))
+		})
+	})
+
+	Describe("Keys", func() {
+		It("lists the keys with the prefix", func() {
+			server.RouteToHandler("POST", "/v3beta/kv/range", ghttp.CombineHandlers(
+				// "/registry/" and "/registry0" (the range end), base64 encoded.
+				ghttp.VerifyJSON(`{"key": "L3JlZ2lzdHJ5Lw==", "range_end": "L3JlZ2lzdHJ5MA==", "keys_only": true}`),
+				ghttp.RespondWithJSONEncoded(http.StatusOK, map[string]interface{}{
+					"kvs": []map[string]interface{}{
+						{"key": []byte("/registry/configmaps/default/a")},
+						{"key": []byte("/registry/namespaces/default")},
+					},
+				}),
+			))
+
+			keys, err := client.Keys("/registry/")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(keys).To(Equal([]string{"/registry/configmaps/default/a", "/registry/namespaces/default"}))
+		})
+	})
+
+	Describe("Delete", func() {
+		It("returns the revision after the deletion", func() {
+			server.RouteToHandler("POST", "/v3beta/kv/deleterange", ghttp.CombineHandlers(
+				ghttp.VerifyJSON(`{"key": "L3JlZ2lzdHJ5L2NvbmZpZ21hcHMvZGVmYXVsdC9h"}`),
+				ghttp.RespondWith(http.StatusOK, `{"header": {"revision": "42"}, "deleted": "1"}`),
+			))
+
+			revision, err := client.Delete("/registry/configmaps/default/a")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(revision).To(BeEquivalentTo(42))
+		})
+	})
+
+	Context("when no gateway can be found", func() {
+		It("returns an error", func() {
+			server.RouteToHandler("POST", "/v3beta/maintenance/status", ghttp.RespondWith(http.StatusNotFound, ""))
+			server.RouteToHandler("POST", "/v3alpha/maintenance/status", ghttp.RespondWith(http.StatusNotFound, ""))
+
+			_, _, err := client.Get("/some/key")
+			Expect(err).To(MatchError(ContainSubstring("could not find etcd's JSON gateway")))
+		})
+	})
+})
@@ -0,0 +1,91 @@
+package integration_tests
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync/atomic"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/kubernetes-sigs/testing_frameworks/integration"
+)
+
+var _ = Describe("AdmissionWebhook", func() {
+	var (
+		controlPlane *integration.ControlPlane
+		webhook      *integration.AdmissionWebhook
+		calls        int32
+	)
+
+	BeforeEach(func() {
+		controlPlane = &integration.ControlPlane{}
+		Expect(controlPlane.Start()).To(Succeed())
+
+		atomic.StoreInt32(&calls, 0)
+		webhook = &integration.AdmissionWebhook{
+			Name: "deny-secrets.testing.k8s.io",
+			Rules: []integration.AdmissionRule{{
+				Operations:  []string{"CREATE"},
+				APIGroups:   []string{""},
+				APIVersions: []string{"v1"},
+				Resources:   []string{"secrets"},
+			}},
+			Handler: http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				atomic.AddInt32(&calls, 1)
+				review := map[string]interface{}{}
+				if err := json.NewDecoder(req.Body).Decode(&review); err != nil {
+					http.Error(rw, err.Error(), http.StatusBadRequest)
+					return
+				}
+				request, _ := review["request"].(map[string]interface{})
+				review["response"] = map[string]interface{}{
+					"uid":     request["uid"],
+					"allowed": false,
+					"status":  map[string]interface{}{"message": "no secrets today"},
+				}
+				delete(review, "request")
+				json.NewEncoder(rw).Encode(review)
+			}),
+		}
+		Expect(controlPlane.StartAdmissionWebhook(webhook)).To(Succeed())
+	})
+	AfterEach(func() {
+		Expect(webhook.Stop()).To(Succeed())
+		Expect(controlPlane.Stop()).To(Succeed())
+	})
+
+	It("is called by the APIServer right after it has been started", func() {
+		_, stderr, err := controlPlane.KubeCtl().Run(
+			"create", "secret", "generic", "some-secret", "--namespace", "default",
+		)
+		Expect(err).To(HaveOccurred())
+		Expect(stderr).To(ContainSubstring("no secrets today"))
+		Expect(atomic.LoadInt32(&calls)).To(BeNumerically("==", 1))
+
+		_, _, err = controlPlane.KubeCtl().Run(
+			"create", "configmap", "some-configmap", "--namespace", "default",
+		)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("leaves neither its probe nor the probe's ConfigMap behind", func() {
+		stdout, _, err := controlPlane.KubeCtl().Run(
+			"get", "configmaps", "--namespace", "default",
+			"--output", "jsonpath={.items[*].metadata.name}",
+		)
+		Expect(err).NotTo(HaveOccurred())
+		configMaps, err := ioutil.ReadAll(stdout)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(strings.Fields(string(configMaps))).NotTo(ContainElement(HavePrefix("webhook-probe-")))
+
+		stdout, _, err = controlPlane.KubeCtl().Run(
+			"get", "validatingwebhookconfiguration", webhook.Name,
+			"--output", "jsonpath={.webhooks[*].name}",
+		)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ioutil.ReadAll(stdout)).To(Equal([]byte(webhook.Name)))
+	})
+})
@@ -0,0 +1,76 @@
+package integration_tests
+
+import (
+	"io/ioutil"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gexec"
+
+	"github.com/kubernetes-sigs/testing_frameworks/integration"
+)
+
+var _ = Describe("AggregatedAPIServer", func() {
+	var controlPlane *integration.ControlPlane
+
+	BeforeEach(func() {
+		controlPlane = &integration.ControlPlane{}
+		Expect(controlPlane.Start()).To(Succeed())
+	})
+	AfterEach(func() {
+		Expect(controlPlane.Stop()).To(Succeed())
+	})
+
+	It("can rely on the APIServer being configured as a front proxy", func() {
+		frontProxyCA, err := ioutil.ReadFile(controlPlane.APIServer.FrontProxyCACertFile())
+		Expect(err).NotTo(HaveOccurred())
+
+		// This is where aggregated APIServers built with k8s.io/apiserver look
+		// up which proxies to trust.
+		configMaps := integration.GroupVersionResource{Version: "v1", Resource: "configmaps"}
+		authentication := controlPlane.Ref(configMaps, "kube-system/extension-apiserver-authentication")
+		Eventually(authentication).Should(integration.HaveField("data.requestheader-client-ca-file", string(frontProxyCA)))
+		Expect(authentication).To(integration.HaveField("data.requestheader-allowed-names", ContainSubstring("front-proxy-client")))
+	})
+
+	It("has requests for its group proxied to it", func() {
+		binary, err := gexec.Build("github.com/kubernetes-sigs/testing_frameworks/integration/internal/integration_tests/testdata/aggregatedapiserver")
+		Expect(err).NotTo(HaveOccurred())
+		defer gexec.CleanupBuildArtifacts()
+
+		wardle := &integration.AggregatedAPIServer{
+			Path:     binary,
+			Group:    "wardle.example.com",
+			Versions: []string{"v1alpha1"},
+		}
+		Expect(controlPlane.StartAggregatedAPIServer(wardle)).To(Succeed())
+
+		client, err := controlPlane.RESTClient()
+		Expect(err).NotTo(HaveOccurred())
+		flunders := integration.GroupVersionResource{Group: "wardle.example.com", Version: "v1alpha1", Resource: "flunders"}
+		list, err := client.List(flunders, "")
+		Expect(err).NotTo(HaveOccurred())
+
+		// The test server names its only flunder after the user the APIServer
+		// passed on.
+		Expect(list.Items()).To(ConsistOf(integration.HaveField("metadata.name", integration.AdminUser.Name)))
+
+		Expect(wardle.Stop()).To(Succeed())
+		Eventually(func() bool {
+			_, err := client.List(flunders, "")
+			return integration.IsNotFound(err)
+		}).Should(BeTrue())
+	})
+
+	It("needs a binary", func() {
+		err := controlPlane.StartAggregatedAPIServer(&integration.AggregatedAPIServer{
+			Path:     "/does/not/exist/wardle-apiserver",
+			Group:    "wardle.example.com",
+			Versions: []string{"v1alpha1"},
+		})
+		Expect(err).To(HaveOccurred())
+
+		apiServices := integration.GroupVersionResource{Group: "apiregistration.k8s.io", Version: "v1", Resource: "apiservices"}
+		Expect(controlPlane.Ref(apiServices, "v1alpha1.wardle.example.com")).To(integration.BeDeleted())
+	})
+})
@@ -0,0 +1,41 @@
+package integration_tests
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/kubernetes-sigs/testing_frameworks/integration"
+)
+
+var _ = Describe("Audit", func() {
+	var controlPlane *integration.ControlPlane
+
+	BeforeEach(func() {
+		controlPlane = &integration.ControlPlane{
+			APIServer: &integration.APIServer{
+				Audit: &integration.Audit{},
+			},
+		}
+		Expect(controlPlane.Start()).To(Succeed())
+	})
+	AfterEach(func() {
+		Expect(controlPlane.Stop()).To(Succeed())
+	})
+
+	It("records the requests made by a user", func() {
+		kubeCtl, err := controlPlane.KubeCtlAs(integration.User{Name: "jane"})
+		Expect(err).NotTo(HaveOccurred())
+		_, _, err = kubeCtl.Run("create", "configmap", "audited", "--namespace", "default")
+		Expect(err).NotTo(HaveOccurred())
+
+		Eventually(controlPlane.APIServer.AuditEvents).Should(integration.HaveAuditEvent(
+			integration.AuditEventFilter{
+				Verb:      "create",
+				Resource:  "configmaps",
+				Namespace: "default",
+				User:      "jane",
+				Code:      201,
+			},
+		))
+	})
+})
@@ -0,0 +1,66 @@
+package integration_tests
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/kubernetes-sigs/testing_frameworks/integration"
+)
+
+var _ = Describe("AuthWebhook", func() {
+	var (
+		controlPlane *integration.ControlPlane
+		webhook      *integration.AuthWebhook
+	)
+
+	BeforeEach(func() {
+		webhook = &integration.AuthWebhook{
+			Authenticate: func(spec integration.TokenReviewSpec) integration.TokenReviewStatus {
+				if spec.Token != "jane-token" {
+					return integration.TokenReviewStatus{}
+				}
+				return integration.TokenReviewStatus{
+					Authenticated: true,
+					User:          integration.UserInfo{Username: "jane"},
+				}
+			},
+			Authorize: func(spec integration.SubjectAccessReviewSpec) integration.SubjectAccessReviewStatus {
+				attrs := spec.ResourceAttributes
+				allowed := spec.User == "jane" && attrs != nil && attrs.Resource == "configmaps"
+				return integration.SubjectAccessReviewStatus{Allowed: allowed}
+			},
+		}
+		controlPlane = &integration.ControlPlane{
+			APIServer: &integration.APIServer{AuthWebhook: webhook},
+		}
+		Expect(controlPlane.Start()).To(Succeed())
+	})
+	AfterEach(func() {
+		Expect(controlPlane.Stop()).To(Succeed())
+	})
+
+	It("lets the test decide on authentication and authorization", func() {
+		kubeCtl := &integration.KubeCtl{Opts: []string{
+			"--server=" + controlPlane.SecureAPIURL().String(),
+			"--certificate-authority=" + controlPlane.APIServer.CACertFile(),
+			"--token=jane-token",
+		}}
+
+		_, _, err := kubeCtl.Run("get", "configmaps", "--namespace", "default")
+		Expect(err).NotTo(HaveOccurred())
+		_, _, err = kubeCtl.Run("get", "secrets", "--namespace", "default")
+		Expect(err).To(HaveOccurred())
+
+		Expect(webhook.TokenReviews()).NotTo(BeEmpty())
+		Expect(webhook.TokenReviews()[0].Status.User.Username).To(Equal("jane"))
+
+		var resources []string
+		for _, review := range webhook.SubjectAccessReviews() {
+			if review.Spec.ResourceAttributes != nil {
+				resources = append(resources, review.Spec.ResourceAttributes.Resource)
+			}
+		}
+		Expect(resources).To(ContainElement("configmaps"))
+		Expect(resources).To(ContainElement("secrets"))
+	})
+})
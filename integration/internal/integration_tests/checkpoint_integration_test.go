@@ -0,0 +1,123 @@
+package integration_tests
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/kubernetes-sigs/testing_frameworks/integration"
+)
+
+var _ = Describe("Checkpoints", func() {
+	var (
+		controlPlane *integration.ControlPlane
+		configMaps   = integration.GroupVersionResource{Version: "v1", Resource: "configmaps"}
+	)
+
+	BeforeEach(func() {
+		controlPlane = &integration.ControlPlane{}
+		Expect(controlPlane.Start()).To(Succeed())
+	})
+	AfterEach(func() {
+		Expect(controlPlane.Stop()).To(Succeed())
+	})
+
+	It("rolls the cluster back to the state it was in at the checkpoint", func() {
+		kubeCtl := controlPlane.KubeCtl()
+		_, _, err := kubeCtl.Run("create", "configmap", "baseline")
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(controlPlane.Checkpoint("baseline")).To(Succeed())
+
+		_, _, err = kubeCtl.Run("create", "configmap", "created-by-a-spec")
+		Expect(err).NotTo(HaveOccurred())
+		_, _, err = kubeCtl.Run("delete", "configmap", "baseline")
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(controlPlane.Rollback("baseline")).To(Succeed())
+
+		Expect(controlPlane.Ref(configMaps, "default/baseline")).To(integration.Exist())
+		Expect(controlPlane.Ref(configMaps, "default/created-by-a-spec")).To(integration.BeDeleted())
+
+		By("serving the restored state from the watch cache, too")
+		client, err := controlPlane.RESTClient()
+		Expect(err).NotTo(HaveOccurred())
+		var cached integration.Object
+		Expect(client.Do("GET", "/api/v1/namespaces/default/configmaps?resourceVersion=0", nil, &cached)).To(Succeed())
+		Expect(cached.Items()).To(HaveLen(1))
+
+		By("allowing to roll back again")
+		_, _, err = kubeCtl.Run("create", "configmap", "created-by-a-spec")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(controlPlane.Rollback("baseline")).To(Succeed())
+		Expect(controlPlane.Ref(configMaps, "default/created-by-a-spec")).To(integration.BeDeleted())
+	})
+
+	It("fails to roll back to an unknown checkpoint", func() {
+		Expect(controlPlane.Rollback("unknown")).To(MatchError(ContainSubstring("no checkpoint")))
+	})
+})
+
+var _ = Describe("Etcd snapshots", func() {
+	It("can be saved, and restored into another Etcd", func() {
+		tmp, err := ioutil.TempDir("", "k8s_test_framework_")
+		Expect(err).NotTo(HaveOccurred())
+		defer os.RemoveAll(tmp)
+		snapshot := filepath.Join(tmp, "snapshot")
+
+		etcd := &integration.Etcd{}
+		Expect(etcd.Start()).To(Succeed())
+		defer func() {
+			Expect(etcd.Stop()).To(Succeed())
+		}()
+		Expect(etcd.SaveSnapshot(snapshot)).To(Succeed())
+
+		restored := &integration.Etcd{}
+		Expect(restored.RestoreSnapshot(snapshot)).To(Succeed())
+		Expect(restored.Start()).To(Succeed())
+		defer func() {
+			Expect(restored.Stop()).To(Succeed())
+		}()
+
+		Expect(snapshot).To(BeADirectory())
+	})
+})
+
+var _ = Describe("Etcd data dir templates", func() {
+	It("lets many ControlPlanes start from the same data, without changing it", func() {
+		configMaps := integration.GroupVersionResource{Version: "v1", Resource: "configmaps"}
+
+		tmp, err := ioutil.TempDir("", "k8s_test_framework_")
+		Expect(err).NotTo(HaveOccurred())
+		defer os.RemoveAll(tmp)
+		template := filepath.Join(tmp, "template")
+
+		golden := &integration.ControlPlane{}
+		Expect(golden.Start()).To(Succeed())
+		_, _, err = golden.KubeCtl().Run("create", "configmap", "golden")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(golden.Etcd.SaveSnapshot(template)).To(Succeed())
+		Expect(golden.Stop()).To(Succeed())
+
+		first := &integration.ControlPlane{Etcd: &integration.Etcd{DataDirTemplate: template}}
+		second := &integration.ControlPlane{Etcd: &integration.Etcd{DataDirTemplate: template}}
+		for _, cp := range []*integration.ControlPlane{first, second} {
+			Expect(cp.Start()).To(Succeed())
+			defer cp.Stop()
+			Expect(cp.Ref(configMaps, "default/golden")).To(integration.Exist())
+		}
+
+		_, _, err = first.KubeCtl().Run("delete", "configmap", "golden")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(first.Ref(configMaps, "default/golden")).To(integration.BeDeleted())
+		Expect(second.Ref(configMaps, "default/golden")).To(integration.Exist())
+
+		third := &integration.ControlPlane{Etcd: &integration.Etcd{DataDirTemplate: template}}
+		Expect(third.Start()).To(Succeed())
+		defer third.Stop()
+		Expect(third.Ref(configMaps, "default/golden")).To(integration.Exist())
+	})
+})
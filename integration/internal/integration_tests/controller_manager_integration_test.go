@@ -0,0 +1,61 @@
+package integration_tests
+
+import (
+	"fmt"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/kubernetes-sigs/testing_frameworks/integration"
+)
+
+var _ = Describe("ControllerManager", func() {
+	var controlPlane *integration.ControlPlane
+
+	BeforeEach(func() {
+		controlPlane = &integration.ControlPlane{
+			ControllerManager: &integration.ControllerManager{},
+		}
+		Expect(controlPlane.Start()).To(Succeed())
+	})
+	AfterEach(func() {
+		Expect(controlPlane.Stop()).To(Succeed())
+	})
+
+	It("runs the controllers", func() {
+		configMaps := integration.GroupVersionResource{Version: "v1", Resource: "configmaps"}
+		namespaces := integration.GroupVersionResource{Version: "v1", Resource: "namespaces"}
+		serviceAccounts := integration.GroupVersionResource{Version: "v1", Resource: "serviceaccounts"}
+
+		client, err := controlPlane.RESTClient()
+		Expect(err).NotTo(HaveOccurred())
+
+		By("creating the default ServiceAccount of new namespaces")
+		_, _, err = controlPlane.KubeCtl().Run("create", "namespace", "widgets")
+		Expect(err).NotTo(HaveOccurred())
+		Eventually(controlPlane.Ref(serviceAccounts, "widgets/default"), "10s").Should(integration.Exist())
+
+		By("collecting the garbage")
+		owner, err := client.Create(configMaps, "widgets", integration.Object{
+			"metadata": map[string]interface{}{"name": "owner"},
+		})
+		Expect(err).NotTo(HaveOccurred())
+		uid, _ := owner.Field("metadata.uid")
+		_, err = client.Create(configMaps, "widgets", integration.Object{
+			"metadata": map[string]interface{}{
+				"name": "dependent",
+				"ownerReferences": []interface{}{map[string]interface{}{
+					"apiVersion": "v1", "kind": "ConfigMap", "name": "owner", "uid": fmt.Sprint(uid),
+				}},
+			},
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(client.Delete(configMaps, "widgets", "owner")).To(Succeed())
+		Eventually(controlPlane.Ref(configMaps, "widgets/dependent"), "30s").Should(integration.BeDeleted())
+
+		By("finishing the deletion of namespaces")
+		_, _, err = controlPlane.KubeCtl().Run("delete", "namespace", "widgets", "--wait=false")
+		Expect(err).NotTo(HaveOccurred())
+		Eventually(controlPlane.Ref(namespaces, "widgets"), "30s").Should(integration.BeDeleted())
+	})
+})
@@ -0,0 +1,72 @@
+package integration_tests
+
+import (
+	"fmt"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/kubernetes-sigs/testing_frameworks/integration"
+)
+
+var _ = Describe("Controllers", func() {
+	var (
+		controlPlane *integration.ControlPlane
+
+		configMaps      = integration.GroupVersionResource{Version: "v1", Resource: "configmaps"}
+		namespaces      = integration.GroupVersionResource{Version: "v1", Resource: "namespaces"}
+		serviceAccounts = integration.GroupVersionResource{Version: "v1", Resource: "serviceaccounts"}
+	)
+
+	BeforeEach(func() {
+		controlPlane = &integration.ControlPlane{
+			Controllers: &integration.Controllers{
+				Namespaces:       true,
+				ServiceAccounts:  true,
+				GarbageCollector: true,
+			},
+		}
+		Expect(controlPlane.Start()).To(Succeed())
+	})
+	AfterEach(func() {
+		Expect(controlPlane.Controllers.Err()).NotTo(HaveOccurred())
+		Expect(controlPlane.Stop()).To(Succeed())
+	})
+
+	It("stand in for the basic controllers of a kube-controller-manager", func() {
+		kubeCtl := controlPlane.KubeCtl()
+		client, err := controlPlane.RESTClient()
+		Expect(err).NotTo(HaveOccurred())
+
+		By("creating the default ServiceAccount of new namespaces")
+		_, _, err = kubeCtl.Run("create", "namespace", "widgets")
+		Expect(err).NotTo(HaveOccurred())
+		Eventually(controlPlane.Ref(serviceAccounts, "widgets/default")).Should(integration.Exist())
+
+		By("collecting the garbage")
+		owner, err := client.Create(configMaps, "widgets", integration.Object{
+			"metadata": map[string]interface{}{"name": "owner"},
+		})
+		Expect(err).NotTo(HaveOccurred())
+		uid, _ := owner.Field("metadata.uid")
+		_, err = client.Create(configMaps, "widgets", integration.Object{
+			"metadata": map[string]interface{}{
+				"name": "dependent",
+				"ownerReferences": []interface{}{map[string]interface{}{
+					"apiVersion": "v1", "kind": "ConfigMap", "name": "owner", "uid": fmt.Sprint(uid),
+				}},
+			},
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Consistently(controlPlane.Ref(configMaps, "widgets/dependent"), "1s").Should(integration.Exist())
+
+		_, _, err = kubeCtl.Run("delete", "configmap", "owner", "--namespace", "widgets")
+		Expect(err).NotTo(HaveOccurred())
+		Eventually(controlPlane.Ref(configMaps, "widgets/dependent"), "5s").Should(integration.BeDeleted())
+
+		By("finishing the deletion of namespaces")
+		_, _, err = kubeCtl.Run("delete", "namespace", "widgets", "--wait=false")
+		Expect(err).NotTo(HaveOccurred())
+		Eventually(controlPlane.Ref(namespaces, "widgets")).Should(integration.BeDeleted())
+	})
+})
@@ -0,0 +1,69 @@
+package integration_tests
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/kubernetes-sigs/testing_frameworks/integration"
+)
+
+var _ = Describe("Encryption at rest", func() {
+	var (
+		controlPlane     *integration.ControlPlane
+		encryptionConfig *integration.EncryptionConfig
+	)
+
+	BeforeEach(func() {
+		encryptionConfig = &integration.EncryptionConfig{
+			Providers: []integration.EncryptionProvider{
+				{Type: "aescbc"},
+				{Type: "identity"},
+			},
+		}
+		controlPlane = &integration.ControlPlane{
+			APIServer: &integration.APIServer{EncryptionConfig: encryptionConfig},
+		}
+		Expect(controlPlane.Start()).To(Succeed())
+	})
+	AfterEach(func() {
+		Expect(controlPlane.Stop()).To(Succeed())
+	})
+
+	rawSecret := func() string {
+		raw, err := controlPlane.Etcd.RawValue(integration.StorageKey("secrets", "default", "precious"))
+		ExpectWithOffset(1, err).NotTo(HaveOccurred())
+		return string(raw)
+	}
+
+	It("encrypts secrets and supports key rotation", func() {
+		kubeCtl := controlPlane.KubeCtl()
+		_, _, err := kubeCtl.Run(
+			"create", "secret", "generic", "precious", "--namespace", "default",
+			"--from-literal=password=very-secret-value",
+		)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(rawSecret()).To(HavePrefix(encryptionConfig.Providers[0].StoragePrefix()))
+		Expect(rawSecret()).NotTo(ContainSubstring("very-secret-value"))
+
+		By("rotating to a new key and restarting the APIServer")
+		newKey, err := integration.NewEncryptionKey("key2")
+		Expect(err).NotTo(HaveOccurred())
+		aescbc := &encryptionConfig.Providers[0]
+		aescbc.Keys = append([]integration.EncryptionKey{newKey}, aescbc.Keys...)
+
+		Expect(controlPlane.APIServer.Stop()).To(Succeed())
+		Expect(controlPlane.APIServer.Start()).To(Succeed())
+
+		By("still being able to read the secret written with the old key")
+		_, _, err = kubeCtl.Run("get", "secret", "precious", "--namespace", "default")
+		Expect(err).NotTo(HaveOccurred())
+
+		By("re-encrypting the secret with the new key when it is written")
+		_, _, err = kubeCtl.Run(
+			"label", "secret", "precious", "--namespace", "default", "rotated=true",
+		)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(rawSecret()).To(HavePrefix("k8s:enc:aescbc:v1:key2:"))
+	})
+})
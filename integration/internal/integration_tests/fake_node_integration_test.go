@@ -0,0 +1,51 @@
+package integration_tests
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/kubernetes-sigs/testing_frameworks/integration"
+)
+
+var _ = Describe("FakeNodes", func() {
+	var (
+		controlPlane *integration.ControlPlane
+
+		nodes  = integration.GroupVersionResource{Version: "v1", Resource: "nodes"}
+		leases = integration.GroupVersionResource{Group: "coordination.k8s.io", Version: "v1", Resource: "leases"}
+	)
+
+	BeforeEach(func() {
+		controlPlane = &integration.ControlPlane{}
+		Expect(controlPlane.Start()).To(Succeed())
+	})
+	AfterEach(func() {
+		Expect(controlPlane.Stop()).To(Succeed())
+	})
+
+	It("register Nodes which look alive", func() {
+		fakeNodes, err := controlPlane.RegisterFakeNodes(2, integration.FakeNodeOptions{
+			Labels: map[string]string{"zone": "a"},
+			Taints: []integration.Taint{{Key: "dedicated", Value: "gpu", Effect: "NoSchedule"}},
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(fakeNodes).To(HaveLen(2))
+		Expect(fakeNodes[0].Name).To(Equal("fake-node-1"))
+		Expect(fakeNodes[1].Name).To(Equal("fake-node-2"))
+
+		node := controlPlane.Ref(nodes, "fake-node-1")
+		Expect(node).To(integration.HaveCondition("Ready", "True"))
+		Expect(node).To(integration.HaveField("metadata.labels.zone", "a"))
+		Expect(node).To(integration.HaveField("status.allocatable.pods", "110"))
+		Expect(controlPlane.Ref(leases, "kube-node-lease/fake-node-1")).To(integration.Exist())
+
+		By("reporting a Node as not ready")
+		Expect(fakeNodes[0].SetReady(false)).To(Succeed())
+		Expect(node).To(integration.HaveCondition("Ready", "False"))
+
+		By("deleting a Node")
+		Expect(fakeNodes[1].Delete()).To(Succeed())
+		Expect(controlPlane.Ref(nodes, "fake-node-2")).To(integration.BeDeleted())
+		Expect(fakeNodes[0].Err()).NotTo(HaveOccurred())
+	})
+})
@@ -0,0 +1,45 @@
+package integration_tests
+
+import (
+	"fmt"
+	"io/ioutil"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/kubernetes-sigs/testing_frameworks/integration"
+)
+
+var _ = Describe("ControlPlane's kubeconfig", func() {
+	var controlPlane *integration.ControlPlane
+
+	BeforeEach(func() {
+		controlPlane = &integration.ControlPlane{
+			APIServer: &integration.APIServer{AuthorizationMode: "RBAC"},
+		}
+		Expect(controlPlane.Start()).To(Succeed())
+	})
+	AfterEach(func() {
+		Expect(controlPlane.Stop()).To(Succeed())
+	})
+
+	It("configures clients as an admin against the secure URL", func() {
+		kubeCtl := &integration.KubeCtl{
+			Opts: []string{fmt.Sprintf("--kubeconfig=%s", controlPlane.KubeConfigFile())},
+		}
+
+		run := func(args ...string) string {
+			stdout, _, err := kubeCtl.Run(args...)
+			ExpectWithOffset(1, err).NotTo(HaveOccurred())
+			out, err := ioutil.ReadAll(stdout)
+			ExpectWithOffset(1, err).NotTo(HaveOccurred())
+			return string(out)
+		}
+
+		Expect(run("config", "view", "--minify", "--output", "jsonpath={.clusters[0].cluster.server}")).
+			To(Equal(controlPlane.SecureAPIURL().String()))
+		Expect(run("auth", "can-i", "delete", "namespaces")).To(HavePrefix("yes"))
+
+		run("get", "serviceaccounts")
+	})
+})
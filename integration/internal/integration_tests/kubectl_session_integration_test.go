@@ -0,0 +1,34 @@
+package integration_tests
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
+
+	"github.com/kubernetes-sigs/testing_frameworks/integration"
+)
+
+var _ = Describe("KubeCtl sessions", func() {
+	var controlPlane *integration.ControlPlane
+
+	BeforeEach(func() {
+		controlPlane = &integration.ControlPlane{}
+		Expect(controlPlane.Start()).To(Succeed())
+	})
+	AfterEach(func() {
+		Expect(controlPlane.Stop()).To(Succeed())
+	})
+
+	It("streams the output of watches", func() {
+		kubeCtl := controlPlane.KubeCtl()
+
+		session, err := kubeCtl.Start("get", "configmaps", "--namespace", "default", "--watch")
+		Expect(err).NotTo(HaveOccurred())
+		defer session.Terminate().Wait()
+
+		_, _, err = kubeCtl.Run("create", "configmap", "watched", "--namespace", "default")
+		Expect(err).NotTo(HaveOccurred())
+
+		Eventually(session, "10s").Should(gbytes.Say("watched"))
+	})
+})
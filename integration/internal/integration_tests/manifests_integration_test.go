@@ -0,0 +1,93 @@
+package integration_tests
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/kubernetes-sigs/testing_frameworks/integration"
+)
+
+var _ = Describe("Manifests", func() {
+	var controlPlane *integration.ControlPlane
+
+	BeforeEach(func() {
+		controlPlane = &integration.ControlPlane{}
+		Expect(controlPlane.Start()).To(Succeed())
+	})
+	AfterEach(func() {
+		Expect(controlPlane.Stop()).To(Succeed())
+	})
+
+	It("applies manifests in dependency order and deletes them again", func() {
+		applied, err := controlPlane.ApplyManifests("testdata/manifests")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(applied.Objects).To(HaveLen(4))
+
+		kubeCtl := controlPlane.KubeCtl()
+		widget, err := kubeCtl.GetObject("get", "widgets", "my-widget", "--namespace", "widgets")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(widget).To(integration.HaveField("spec.size", 3))
+
+		Expect(applied.Delete()).To(Succeed())
+
+		Eventually(func() error {
+			_, _, err := kubeCtl.Run("get", "configmap", "widget-config", "--namespace", "widgets")
+			return err
+		}, "10s").Should(MatchError(ContainSubstring("NotFound")))
+	})
+
+	It("keeps the objects which existed before", func() {
+		kubeCtl := controlPlane.KubeCtl()
+		_, _, err := kubeCtl.Run("create", "configmap", "existing", "--namespace", "default")
+		Expect(err).NotTo(HaveOccurred())
+
+		applied, err := controlPlane.ApplyManifestData([]byte(`
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: existing
+  namespace: default
+data:
+  changed: "true"
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: new
+  namespace: default
+`))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(applied.Delete()).To(Succeed())
+
+		_, _, err = kubeCtl.Run("get", "configmap", "existing", "--namespace", "default")
+		Expect(err).NotTo(HaveOccurred())
+		Eventually(func() error {
+			_, _, err := kubeCtl.Run("get", "configmap", "new", "--namespace", "default")
+			return err
+		}, "10s").Should(MatchError(ContainSubstring("NotFound")))
+	})
+
+	It("deletes the objects created before applying failed", func() {
+		applied, err := controlPlane.ApplyManifestData([]byte(`
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: valid
+  namespace: default
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: Not_A_Valid_Name
+  namespace: default
+`))
+		Expect(err).To(HaveOccurred())
+		Expect(applied.Delete()).To(Succeed())
+
+		kubeCtl := controlPlane.KubeCtl()
+		Eventually(func() error {
+			_, _, err := kubeCtl.Run("get", "configmap", "valid", "--namespace", "default")
+			return err
+		}, "10s").Should(MatchError(ContainSubstring("NotFound")))
+	})
+})
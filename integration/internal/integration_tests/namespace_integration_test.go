@@ -0,0 +1,46 @@
+package integration_tests
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/kubernetes-sigs/testing_frameworks/integration"
+)
+
+var _ = Describe("Namespace", func() {
+	var controlPlane *integration.ControlPlane
+
+	BeforeEach(func() {
+		controlPlane = &integration.ControlPlane{}
+		Expect(controlPlane.Start()).To(Succeed())
+	})
+	AfterEach(func() {
+		Expect(controlPlane.Stop()).To(Succeed())
+	})
+
+	It("gives each spec a namespace of its own, which can be deleted without a namespace controller", func() {
+		configMaps := integration.GroupVersionResource{Version: "v1", Resource: "configmaps"}
+		namespaces := integration.GroupVersionResource{Version: "v1", Resource: "namespaces"}
+
+		first, err := controlPlane.CreateNamespace("widgets")
+		Expect(err).NotTo(HaveOccurred())
+		second, err := controlPlane.CreateNamespace("widgets")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(first.Name).To(HavePrefix("widgets-"))
+		Expect(second.Name).NotTo(Equal(first.Name))
+
+		_, _, err = first.KubeCtl().Run("create", "configmap", "my-widget")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(controlPlane.Ref(configMaps, first.Name+"/my-widget")).To(integration.Exist())
+		Expect(controlPlane.Ref(configMaps, second.Name+"/my-widget")).To(integration.BeDeleted())
+
+		_, _, err = first.KubeCtl().Run("patch", "configmap", "my-widget",
+			"--type=merge", "--patch", `{"metadata": {"finalizers": ["example.com/cleanup"]}}`)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(first.Delete()).To(Succeed())
+		Expect(controlPlane.Ref(namespaces, first.Name)).To(integration.BeDeleted())
+		Expect(controlPlane.Ref(configMaps, first.Name+"/my-widget")).To(integration.BeDeleted())
+		Expect(controlPlane.Ref(namespaces, second.Name)).To(integration.Exist())
+	})
+})
@@ -0,0 +1,39 @@
+package integration_tests
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/kubernetes-sigs/testing_frameworks/integration"
+)
+
+var _ = Describe("KubeCtl output decoding", func() {
+	var controlPlane *integration.ControlPlane
+
+	BeforeEach(func() {
+		controlPlane = &integration.ControlPlane{}
+		Expect(controlPlane.Start()).To(Succeed())
+	})
+	AfterEach(func() {
+		Expect(controlPlane.Stop()).To(Succeed())
+	})
+
+	It("decodes objects and lists", func() {
+		kubeCtl := controlPlane.KubeCtl()
+		_, _, err := kubeCtl.Run("create", "configmap", "decoded", "--namespace", "default", "--from-literal", "key=value")
+		Expect(err).NotTo(HaveOccurred())
+
+		configMap, err := kubeCtl.GetObject("get", "configmap", "decoded", "--namespace", "default")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(configMap.Kind()).To(Equal("ConfigMap"))
+		Expect(configMap).To(integration.HaveField("data.key", "value"))
+
+		list, err := kubeCtl.GetObject("get", "configmaps", "--namespace", "default")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(list.Items()).To(ContainElement(integration.HaveField("metadata.name", "decoded")))
+
+		uid, err := kubeCtl.JSONPath("{.metadata.uid}", "get", "configmap", "decoded", "--namespace", "default")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(configMap).To(integration.HaveField("metadata.uid", uid))
+	})
+})
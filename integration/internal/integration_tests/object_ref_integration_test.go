@@ -0,0 +1,37 @@
+package integration_tests
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/kubernetes-sigs/testing_frameworks/integration"
+)
+
+var _ = Describe("ObjectRef matchers", func() {
+	var controlPlane *integration.ControlPlane
+
+	BeforeEach(func() {
+		controlPlane = &integration.ControlPlane{}
+		Expect(controlPlane.Start()).To(Succeed())
+	})
+	AfterEach(func() {
+		Expect(controlPlane.Stop()).To(Succeed())
+	})
+
+	It("follows the state of objects", func() {
+		configMaps := integration.GroupVersionResource{Version: "v1", Resource: "configmaps"}
+		ref := controlPlane.Ref(configMaps, "default/followed")
+		Expect(ref).To(integration.BeDeleted())
+
+		kubeCtl := controlPlane.KubeCtl()
+		_, _, err := kubeCtl.Run("create", "configmap", "followed", "--namespace", "default", "--from-literal", "key=value")
+		Expect(err).NotTo(HaveOccurred())
+
+		Eventually(ref).Should(integration.Exist())
+		Expect(ref).To(integration.HaveField("{.data.key}", "value"))
+
+		_, _, err = kubeCtl.Run("delete", "configmap", "followed", "--namespace", "default")
+		Expect(err).NotTo(HaveOccurred())
+		Eventually(ref).Should(integration.BeDeleted())
+	})
+})
@@ -0,0 +1,48 @@
+package integration_tests
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/kubernetes-sigs/testing_frameworks/integration"
+)
+
+var _ = Describe("OIDCIssuer", func() {
+	var (
+		controlPlane *integration.ControlPlane
+		issuer       *integration.OIDCIssuer
+	)
+
+	BeforeEach(func() {
+		issuer = &integration.OIDCIssuer{}
+		controlPlane = &integration.ControlPlane{
+			APIServer: &integration.APIServer{
+				AuthorizationMode: "RBAC",
+				OIDCIssuer:        issuer,
+			},
+		}
+		Expect(controlPlane.Start()).To(Succeed())
+	})
+	AfterEach(func() {
+		Expect(controlPlane.Stop()).To(Succeed())
+	})
+
+	It("authenticates users with ID tokens", func() {
+		token, err := issuer.IDToken(map[string]interface{}{
+			"sub":    "jane",
+			"groups": []string{"devs"},
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(controlPlane.GrantClusterRole("view", integration.GroupSubject("devs"))).To(Succeed())
+
+		kubeCtl := controlPlane.KubeCtlWithToken(token)
+		Eventually(func() error {
+			_, _, err := kubeCtl.Run("get", "pods", "--namespace", "default")
+			return err
+		}, "10s").Should(Succeed())
+
+		stdout, _, _ := kubeCtl.Run("auth", "can-i", "delete", "pods", "--namespace", "default")
+		Expect(stdout).To(ContainSubstring("no"))
+	})
+})
@@ -0,0 +1,53 @@
+package integration_tests
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
+
+	"github.com/kubernetes-sigs/testing_frameworks/integration"
+)
+
+var _ = Describe("Process", func() {
+	var (
+		controlPlane *integration.ControlPlane
+		watcher      *integration.Process
+		out          *gbytes.Buffer
+	)
+
+	BeforeEach(func() {
+		out = gbytes.NewBuffer()
+		// Any binary which connects to the APIServer with the kubeconfig it
+		// is given will do, so a watching kubectl stands in for a controller.
+		watcher = &integration.Process{
+			Name: "kubectl",
+			Args: []string{"get", "configmaps", "--namespace=default", "--watch", "--output=name"},
+			Out:  out,
+		}
+		controlPlane = &integration.ControlPlane{
+			Processes: []*integration.Process{watcher},
+		}
+		Expect(controlPlane.Start()).To(Succeed())
+	})
+	AfterEach(func() {
+		Expect(controlPlane.Stop()).To(Succeed())
+	})
+
+	It("runs a binary of our own, connected to the APIServer", func() {
+		Expect(watcher.KubeConfigFile).To(BeAnExistingFile())
+
+		_, _, err := controlPlane.KubeCtl().Run("create", "configmap", "my-config")
+		Expect(err).NotTo(HaveOccurred())
+		Eventually(out, "10s").Should(gbytes.Say("configmap/my-config"))
+
+		By("starting another one on the running ControlPlane")
+		other := &integration.Process{
+			Name: "kubectl",
+			Args: []string{"get", "configmaps", "--namespace=default", "--watch", "--output=name"},
+			Out:  gbytes.NewBuffer(),
+		}
+		Expect(controlPlane.StartProcess(other)).To(Succeed())
+		Eventually(other.Out, "10s").Should(gbytes.Say("configmap/my-config"))
+		Expect(controlPlane.Processes).To(ConsistOf(watcher, other))
+	})
+})
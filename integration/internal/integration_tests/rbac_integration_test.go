@@ -0,0 +1,54 @@
+package integration_tests
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/kubernetes-sigs/testing_frameworks/integration"
+)
+
+var _ = Describe("RBAC", func() {
+	var controlPlane *integration.ControlPlane
+
+	BeforeEach(func() {
+		controlPlane = &integration.ControlPlane{
+			APIServer: &integration.APIServer{AuthorizationMode: "RBAC"},
+		}
+		Expect(controlPlane.Start()).To(Succeed())
+	})
+	AfterEach(func() {
+		Expect(controlPlane.Stop()).To(Succeed())
+	})
+
+	It("grants roles to users and groups", func() {
+		jane := integration.User{Name: "jane", Groups: []string{"devs"}}
+		joe := integration.User{Name: "joe"}
+
+		By("denying access to users without any grants")
+		Expect(controlPlane.CanI(jane, "list", "pods", "default")).To(BeFalse())
+
+		By("granting a role in a namespace to a group")
+		Expect(controlPlane.CreateRole("default", "pod-reader", integration.PolicyRule{
+			Verbs:     []string{"get", "list"},
+			Resources: []string{"pods"},
+		})).To(Succeed())
+		Expect(controlPlane.GrantRole("default", "pod-reader", integration.GroupSubject("devs"))).To(Succeed())
+
+		Expect(controlPlane.CanI(jane, "list", "pods", "default")).To(BeTrue())
+		Expect(controlPlane.CanI(jane, "list", "pods", "kube-system")).To(BeFalse())
+		Expect(controlPlane.CanI(jane, "delete", "pods", "default")).To(BeFalse())
+		Expect(controlPlane.CanI(joe, "list", "pods", "default")).To(BeFalse())
+
+		By("granting a cluster role cluster wide to a user")
+		Expect(controlPlane.GrantClusterRole("view", joe.Subject())).To(Succeed())
+		Expect(controlPlane.CanI(joe, "list", "pods", "")).To(BeTrue())
+
+		By("running kubectl as the user")
+		kubeCtl, err := controlPlane.KubeCtlAs(jane)
+		Expect(err).NotTo(HaveOccurred())
+		_, _, err = kubeCtl.Run("get", "pods", "--namespace", "default")
+		Expect(err).NotTo(HaveOccurred())
+		_, _, err = kubeCtl.Run("get", "pods", "--namespace", "kube-system")
+		Expect(err).To(HaveOccurred())
+	})
+})
@@ -0,0 +1,53 @@
+package integration_tests
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/kubernetes-sigs/testing_frameworks/integration"
+)
+
+var _ = Describe("Reset", func() {
+	var controlPlane *integration.ControlPlane
+
+	BeforeEach(func() {
+		controlPlane = &integration.ControlPlane{}
+		Expect(controlPlane.Start()).To(Succeed())
+	})
+	AfterEach(func() {
+		Expect(controlPlane.Stop()).To(Succeed())
+	})
+
+	It("deletes the objects created since the start, and keeps the rest", func() {
+		configMaps := integration.GroupVersionResource{Version: "v1", Resource: "configmaps"}
+		namespaces := integration.GroupVersionResource{Version: "v1", Resource: "namespaces"}
+
+		kubeCtl := controlPlane.KubeCtl()
+		for _, args := range [][]string{
+			{"create", "namespace", "leaked"},
+			{"create", "configmap", "leaked", "--namespace", "leaked"},
+			{"create", "configmap", "leaked", "--namespace", "default"},
+		} {
+			_, _, err := kubeCtl.Run(args...)
+			Expect(err).NotTo(HaveOccurred())
+		}
+
+		Expect(controlPlane.Reset()).To(Succeed())
+
+		Expect(controlPlane.Ref(namespaces, "leaked")).To(integration.BeDeleted())
+		Expect(controlPlane.Ref(configMaps, "leaked/leaked")).To(integration.BeDeleted())
+		Expect(controlPlane.Ref(configMaps, "default/leaked")).To(integration.BeDeleted())
+		Expect(controlPlane.Ref(namespaces, "default")).To(integration.Exist())
+		Expect(controlPlane.Ref(namespaces, "kube-system")).To(integration.Exist())
+
+		client, err := controlPlane.RESTClient()
+		Expect(err).NotTo(HaveOccurred())
+		var cached integration.Object
+		Expect(client.Do("GET", "/api/v1/configmaps?resourceVersion=0", nil, &cached)).To(Succeed())
+		Expect(cached.Items()).To(BeEmpty())
+
+		By("allowing to create the same objects again")
+		_, _, err = kubeCtl.Run("create", "namespace", "leaked")
+		Expect(err).NotTo(HaveOccurred())
+	})
+})
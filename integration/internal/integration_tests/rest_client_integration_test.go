@@ -0,0 +1,66 @@
+package integration_tests
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/kubernetes-sigs/testing_frameworks/integration"
+)
+
+var _ = Describe("RESTClient", func() {
+	var (
+		controlPlane *integration.ControlPlane
+		configMaps   = integration.GroupVersionResource{Version: "v1", Resource: "configmaps"}
+	)
+
+	BeforeEach(func() {
+		controlPlane = &integration.ControlPlane{
+			APIServer: &integration.APIServer{AuthorizationMode: "RBAC"},
+		}
+		Expect(controlPlane.Start()).To(Succeed())
+	})
+	AfterEach(func() {
+		Expect(controlPlane.Stop()).To(Succeed())
+	})
+
+	It("manages objects", func() {
+		client, err := controlPlane.RESTClient()
+		Expect(err).NotTo(HaveOccurred())
+
+		created, err := client.Create(configMaps, "default", integration.Object{
+			"apiVersion": "v1",
+			"kind":       "ConfigMap",
+			"metadata":   map[string]interface{}{"name": "rest"},
+			"data":       map[string]interface{}{"key": "value"},
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(created).To(integration.HaveField("metadata.uid", Not(BeEmpty())))
+
+		_, err = client.Create(configMaps, "default", created)
+		Expect(integration.IsAlreadyExists(err)).To(BeTrue())
+
+		patched, err := client.Patch(configMaps, "default", "rest", integration.MergePatchType, []byte(`{"data": {"key": "patched"}}`))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(patched).To(integration.HaveField("data.key", "patched"))
+
+		_, err = client.Update(configMaps, "default", created)
+		Expect(integration.IsConflict(err)).To(BeTrue())
+
+		list, err := client.List(configMaps, "default")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(list.Items()).To(ContainElement(integration.HaveField("metadata.name", "rest")))
+
+		Expect(client.Delete(configMaps, "default", "rest")).To(Succeed())
+		_, err = client.Get(configMaps, "default", "rest")
+		Expect(integration.IsNotFound(err)).To(BeTrue())
+	})
+
+	It("authenticates as users", func() {
+		client, err := controlPlane.RESTClientAs(integration.User{Name: "jane"})
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = client.List(configMaps, "default")
+		Expect(err).To(HaveOccurred())
+		Expect(err.(*integration.StatusError).Reason).To(Equal("Forbidden"))
+	})
+})
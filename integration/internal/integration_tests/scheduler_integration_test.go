@@ -0,0 +1,55 @@
+package integration_tests
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/kubernetes-sigs/testing_frameworks/integration"
+)
+
+var _ = Describe("Scheduler", func() {
+	var controlPlane *integration.ControlPlane
+
+	BeforeEach(func() {
+		controlPlane = &integration.ControlPlane{
+			Scheduler: &integration.Scheduler{},
+		}
+		Expect(controlPlane.Start()).To(Succeed())
+	})
+	AfterEach(func() {
+		Expect(controlPlane.Stop()).To(Succeed())
+	})
+
+	It("schedules pods onto nodes", func() {
+		nodes := integration.GroupVersionResource{Version: "v1", Resource: "nodes"}
+		pods := integration.GroupVersionResource{Version: "v1", Resource: "pods"}
+
+		client, err := controlPlane.RESTClient()
+		Expect(err).NotTo(HaveOccurred())
+
+		resources := map[string]interface{}{"cpu": "4", "memory": "8Gi", "pods": "110"}
+		_, err = client.Create(nodes, "", integration.Object{
+			"metadata": map[string]interface{}{"name": "node-1"},
+			"status": map[string]interface{}{
+				"capacity":    resources,
+				"allocatable": resources,
+				"conditions": []interface{}{
+					map[string]interface{}{"type": "Ready", "status": "True"},
+				},
+			},
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = client.Create(pods, "default", integration.Object{
+			"metadata": map[string]interface{}{"name": "web"},
+			"spec": map[string]interface{}{
+				"containers": []interface{}{
+					map[string]interface{}{"name": "web", "image": "nginx"},
+				},
+			},
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		Eventually(controlPlane.Ref(pods, "default/web"), "10s").Should(integration.HaveField("spec.nodeName", "node-1"))
+	})
+})
@@ -0,0 +1,43 @@
+package integration_tests
+
+import (
+	"io/ioutil"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/kubernetes-sigs/testing_frameworks/integration"
+)
+
+var _ = Describe("ServiceAccounts", func() {
+	var controlPlane *integration.ControlPlane
+
+	BeforeEach(func() {
+		controlPlane = &integration.ControlPlane{
+			APIServer: &integration.APIServer{AuthorizationMode: "RBAC"},
+		}
+		Expect(controlPlane.Start()).To(Succeed())
+	})
+	AfterEach(func() {
+		Expect(controlPlane.Stop()).To(Succeed())
+	})
+
+	It("authenticates with a requested token", func() {
+		kubeCtl, err := controlPlane.KubeCtlForServiceAccount("default", "robot")
+		Expect(err).NotTo(HaveOccurred())
+
+		stdout, _, err := kubeCtl.Run("auth", "can-i", "get", "pods", "--namespace", "default")
+		Expect(err).To(HaveOccurred())
+		Expect(ioutil.ReadAll(stdout)).To(Equal([]byte("no\n")))
+
+		Expect(controlPlane.GrantClusterRoleInNamespace(
+			"default", "view",
+			integration.UserSubject("system:serviceaccount:default:robot"),
+		)).To(Succeed())
+
+		stdout, _, err = kubeCtl.Run("get", "pods", "--namespace", "default")
+		Expect(err).NotTo(HaveOccurred())
+		_, err = ioutil.ReadAll(stdout)
+		Expect(err).NotTo(HaveOccurred())
+	})
+})
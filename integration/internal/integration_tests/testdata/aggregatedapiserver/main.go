@@ -0,0 +1,124 @@
+// Command aggregatedapiserver is a minimal stand-in for an extension apiserver,
+// which understands the default arguments of an AggregatedAPIServer. It
+// serves discovery for any group and version it is asked for, with a single
+// "flunders" resource, and lists one flunder named after the user a request
+// is made for.
+package main
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+)
+
+func main() {
+	var (
+		port            = flag.String("secure-port", "", "")
+		bindAddress     = flag.String("bind-address", "", "")
+		certFile        = flag.String("tls-cert-file", "", "")
+		keyFile         = flag.String("tls-private-key-file", "", "")
+		requestHeaderCA = flag.String("requestheader-client-ca-file", "", "")
+		allowedNames    = flag.String("requestheader-allowed-names", "", "")
+		usernameHeader  = flag.String("requestheader-username-headers", "", "")
+		_               = flag.String("cert-dir", "", "")
+		_               = flag.String("client-ca-file", "", "")
+		_               = flag.String("requestheader-group-headers", "", "")
+		_               = flag.String("requestheader-extra-headers-prefix", "", "")
+		_               = flag.String("kubeconfig", "", "")
+		_               = flag.String("authentication-kubeconfig", "", "")
+		_               = flag.String("authorization-kubeconfig", "", "")
+	)
+	flag.Parse()
+
+	caPEM, err := ioutil.ReadFile(*requestHeaderCA)
+	if err != nil {
+		fail(err)
+	}
+	proxyCAs := x509.NewCertPool()
+	if !proxyCAs.AppendCertsFromPEM(caPEM) {
+		fail(fmt.Errorf("no certificates in %s", *requestHeaderCA))
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", func(rw http.ResponseWriter, req *http.Request) {
+		rw.Write([]byte("ok"))
+	})
+	mux.HandleFunc("/apis/", func(rw http.ResponseWriter, req *http.Request) {
+		// Only the APIServer, as the front proxy, may tell us who the user is.
+		if req.TLS == nil || len(req.TLS.PeerCertificates) == 0 ||
+			req.TLS.PeerCertificates[0].Subject.CommonName != *allowedNames {
+			http.Error(rw, "expected a client certificate of the front proxy", http.StatusUnauthorized)
+			return
+		}
+		serveAPI(rw, req, req.Header.Get(*usernameHeader))
+	})
+
+	server := &http.Server{
+		Handler: mux,
+		TLSConfig: &tls.Config{
+			ClientAuth: tls.VerifyClientCertIfGiven,
+			ClientCAs:  proxyCAs,
+		},
+	}
+	listener, err := net.Listen("tcp", net.JoinHostPort(*bindAddress, *port))
+	if err != nil {
+		fail(err)
+	}
+	fail(server.ServeTLS(listener, *certFile, *keyFile))
+}
+
+// serveAPI serves "/apis/<group>/<version>" and
+// "/apis/<group>/<version>/flunders".
+func serveAPI(rw http.ResponseWriter, req *http.Request, user string) {
+	segments := strings.Split(strings.Trim(req.URL.Path, "/"), "/")
+	if len(segments) < 3 {
+		http.NotFound(rw, req)
+		return
+	}
+	groupVersion := segments[1] + "/" + segments[2]
+
+	switch {
+	case len(segments) == 3:
+		writeJSON(rw, map[string]interface{}{
+			"kind":         "APIResourceList",
+			"apiVersion":   "v1",
+			"groupVersion": groupVersion,
+			"resources": []interface{}{map[string]interface{}{
+				"name":       "flunders",
+				"kind":       "Flunder",
+				"namespaced": false,
+				"verbs":      []string{"list"},
+			}},
+		})
+	case len(segments) == 4 && segments[3] == "flunders":
+		writeJSON(rw, map[string]interface{}{
+			"kind":       "FlunderList",
+			"apiVersion": groupVersion,
+			"metadata":   map[string]interface{}{},
+			"items": []interface{}{map[string]interface{}{
+				"kind":       "Flunder",
+				"apiVersion": groupVersion,
+				"metadata":   map[string]interface{}{"name": user},
+			}},
+		})
+	default:
+		http.NotFound(rw, req)
+	}
+}
+
+func writeJSON(rw http.ResponseWriter, v interface{}) {
+	rw.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(rw).Encode(v)
+}
+
+func fail(err error) {
+	fmt.Fprintln(os.Stderr, err)
+	os.Exit(1)
+}
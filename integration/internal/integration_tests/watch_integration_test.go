@@ -0,0 +1,51 @@
+package integration_tests
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/kubernetes-sigs/testing_frameworks/integration"
+)
+
+var _ = Describe("Watcher", func() {
+	var controlPlane *integration.ControlPlane
+
+	BeforeEach(func() {
+		controlPlane = &integration.ControlPlane{}
+		Expect(controlPlane.Start()).To(Succeed())
+	})
+	AfterEach(func() {
+		Expect(controlPlane.Stop()).To(Succeed())
+	})
+
+	It("receives changes in order", func() {
+		configMaps := integration.GroupVersionResource{Version: "v1", Resource: "configmaps"}
+		watcher, err := controlPlane.Watch(configMaps, "default", integration.WatchOptions{
+			LabelSelector: "watched=true",
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		kubeCtl := controlPlane.KubeCtl()
+		for _, args := range [][]string{
+			{"create", "configmap", "ignored"},
+			{"create", "configmap", "watched"},
+			{"label", "configmap", "watched", "watched=true"},
+			{"annotate", "configmap", "watched", "step=two"},
+			{"delete", "configmap", "watched"},
+		} {
+			_, _, err := kubeCtl.Run(append(args, "--namespace", "default")...)
+			Expect(err).NotTo(HaveOccurred())
+		}
+
+		Eventually(watcher).Should(integration.ReceiveEvent(integration.EventAdded,
+			integration.HaveField("metadata.name", "watched")))
+		Eventually(watcher).Should(integration.ReceiveEvent(integration.EventModified,
+			integration.HaveField("metadata.annotations.step", "two")))
+		Eventually(watcher).Should(integration.ReceiveEvent(integration.EventDeleted))
+
+		Expect(watcher.Events()).NotTo(ContainElement(WithTransform(
+			func(e integration.WatchEvent) string { return e.Object.Name() },
+			Equal("ignored"),
+		)))
+	})
+})
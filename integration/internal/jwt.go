@@ -0,0 +1,104 @@
+package internal
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+)
+
+// NewRSAKey generates a new RSA private key, suitable to sign JWTs.
+func NewRSAKey() (*rsa.PrivateKey, error) {
+	return rsa.GenerateKey(rand.Reader, 2048)
+}
+
+// RSAKeyAsBytes returns the PEM encoded private and public key.
+func RSAKeyAsBytes(key *rsa.PrivateKey) (private []byte, public []byte, err error) {
+	private = pem.EncodeToMemory(&pem.Block{
+		Type:  "RSA PRIVATE KEY",
+		Bytes: x509.MarshalPKCS1PrivateKey(key),
+	})
+
+	rawPublic, err := x509.MarshalPKIXPublicKey(key.Public())
+	if err != nil {
+		return nil, nil, err
+	}
+	public = pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: rawPublic})
+
+	return private, public, nil
+}
+
+// KeyID returns an identifier for the public part of the key, the same way
+// the kubernetes APIServer derives the "kid" of the tokens it signs.
+func KeyID(key *rsa.PrivateKey) (string, error) {
+	rawPublic, err := x509.MarshalPKIXPublicKey(key.Public())
+	if err != nil {
+		return "", err
+	}
+	hash := sha256.Sum256(rawPublic)
+	return base64.RawURLEncoding.EncodeToString(hash[:]), nil
+}
+
+// SignJWT returns a JWT, signed with RS256, carrying the claims.
+func SignJWT(key *rsa.PrivateKey, claims interface{}) (string, error) {
+	keyID, err := KeyID(key)
+	if err != nil {
+		return "", err
+	}
+
+	header, err := json.Marshal(map[string]string{
+		"alg": "RS256",
+		"typ": "JWT",
+		"kid": keyID,
+	})
+	if err != nil {
+		return "", err
+	}
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+
+	signingInput := base64.RawURLEncoding.EncodeToString(header) + "." +
+		base64.RawURLEncoding.EncodeToString(payload)
+
+	hash := sha256.Sum256([]byte(signingInput))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, hash[:])
+	if err != nil {
+		return "", err
+	}
+
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+// JSONWebKey is the public part of an RSA key, as served in a JWKS.
+type JSONWebKey struct {
+	KeyType   string `json:"kty"`
+	Algorithm string `json:"alg"`
+	Use       string `json:"use"`
+	KeyID     string `json:"kid"`
+	Modulus   string `json:"n"`
+	Exponent  string `json:"e"`
+}
+
+// NewJSONWebKey returns the public part of the key as a JSON web key.
+func NewJSONWebKey(key *rsa.PrivateKey) (JSONWebKey, error) {
+	keyID, err := KeyID(key)
+	if err != nil {
+		return JSONWebKey{}, err
+	}
+
+	return JSONWebKey{
+		KeyType:   "RSA",
+		Algorithm: "RS256",
+		Use:       "sig",
+		KeyID:     keyID,
+		Modulus:   base64.RawURLEncoding.EncodeToString(key.PublicKey.N.Bytes()),
+		Exponent:  base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.PublicKey.E)).Bytes()),
+	}, nil
+}
@@ -0,0 +1,86 @@
+package internal_test
+
+import (
+	"crypto"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"strings"
+
+	. "github.com/kubernetes-sigs/testing_frameworks/integration/internal"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("JWT", func() {
+	var key *rsa.PrivateKey
+	BeforeEach(func() {
+		var err error
+		key, err = NewRSAKey()
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("signs the claims with RS256", func() {
+		token, err := SignJWT(key, map[string]interface{}{"sub": "someone"})
+		Expect(err).NotTo(HaveOccurred())
+
+		parts := strings.Split(token, ".")
+		Expect(parts).To(HaveLen(3))
+
+		decode := func(part string, into interface{}) {
+			raw, err := base64.RawURLEncoding.DecodeString(part)
+			ExpectWithOffset(1, err).NotTo(HaveOccurred())
+			ExpectWithOffset(1, json.Unmarshal(raw, into)).To(Succeed())
+		}
+
+		header := map[string]string{}
+		decode(parts[0], &header)
+		Expect(header).To(HaveKeyWithValue("alg", "RS256"))
+		keyID, err := KeyID(key)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(header).To(HaveKeyWithValue("kid", keyID))
+
+		claims := map[string]string{}
+		decode(parts[1], &claims)
+		Expect(claims).To(HaveKeyWithValue("sub", "someone"))
+
+		signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+		Expect(err).NotTo(HaveOccurred())
+		hash := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+		Expect(rsa.VerifyPKCS1v15(&key.PublicKey, crypto.SHA256, hash[:], signature)).To(Succeed())
+	})
+
+	It("encodes the keys as PEM", func() {
+		private, public, err := RSAKeyAsBytes(key)
+		Expect(err).NotTo(HaveOccurred())
+
+		privateBlock, _ := pem.Decode(private)
+		Expect(privateBlock.Type).To(Equal("RSA PRIVATE KEY"))
+
+		publicBlock, _ := pem.Decode(public)
+		Expect(publicBlock.Type).To(Equal("PUBLIC KEY"))
+		parsed, err := x509.ParsePKIXPublicKey(publicBlock.Bytes)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(parsed).To(Equal(&key.PublicKey))
+	})
+})
+
+var _ = Describe("JSONWebKey", func() {
+	It("holds the public part of the key", func() {
+		key, err := NewRSAKey()
+		Expect(err).NotTo(HaveOccurred())
+
+		jwk, err := NewJSONWebKey(key)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(jwk.KeyType).To(Equal("RSA"))
+		Expect(jwk.Algorithm).To(Equal("RS256"))
+		Expect(jwk.Exponent).To(Equal("AQAB"))
+		modulus, err := base64.RawURLEncoding.DecodeString(jwk.Modulus)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(modulus).To(Equal(key.PublicKey.N.Bytes()))
+	})
+})
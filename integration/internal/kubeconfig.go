@@ -0,0 +1,78 @@
+package internal
+
+import (
+	"io/ioutil"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+// KubeConfig is the subset of a kubeconfig file the framework needs to
+// generate, both for clients and for the webhooks the APIServer calls out to.
+type KubeConfig struct {
+	APIVersion     string            `yaml:"apiVersion"`
+	Kind           string            `yaml:"kind"`
+	Clusters       []NamedCluster    `yaml:"clusters"`
+	Users          []NamedUser       `yaml:"users"`
+	Contexts       []NamedContext    `yaml:"contexts"`
+	CurrentContext string            `yaml:"current-context"`
+	Preferences    map[string]string `yaml:"preferences"`
+}
+
+type NamedCluster struct {
+	Name    string  `yaml:"name"`
+	Cluster Cluster `yaml:"cluster"`
+}
+
+// Cluster describes how to connect to a server. All the *Data fields hold
+// base64 encoded PEM data.
+type Cluster struct {
+	Server                   string `yaml:"server"`
+	CertificateAuthorityData string `yaml:"certificate-authority-data,omitempty"`
+}
+
+type NamedUser struct {
+	Name string   `yaml:"name"`
+	User AuthInfo `yaml:"user"`
+}
+
+// AuthInfo describes how to authenticate against a server. All the *Data
+// fields hold base64 encoded PEM data.
+type AuthInfo struct {
+	ClientCertificateData string `yaml:"client-certificate-data,omitempty"`
+	ClientKeyData         string `yaml:"client-key-data,omitempty"`
+	Token                 string `yaml:"token,omitempty"`
+}
+
+type NamedContext struct {
+	Name    string      `yaml:"name"`
+	Context KubeContext `yaml:"context"`
+}
+
+type KubeContext struct {
+	Cluster   string `yaml:"cluster"`
+	User      string `yaml:"user"`
+	Namespace string `yaml:"namespace,omitempty"`
+}
+
+// NewKubeConfig returns a kubeconfig with a single cluster, user and context,
+// all of them called name.
+func NewKubeConfig(name string, cluster Cluster, user AuthInfo, namespace string) KubeConfig {
+	return KubeConfig{
+		APIVersion:     "v1",
+		Kind:           "Config",
+		Clusters:       []NamedCluster{{Name: name, Cluster: cluster}},
+		Users:          []NamedUser{{Name: name, User: user}},
+		Contexts:       []NamedContext{{Name: name, Context: KubeContext{Cluster: name, User: name, Namespace: namespace}}},
+		CurrentContext: name,
+		Preferences:    map[string]string{},
+	}
+}
+
+// WriteKubeConfig serializes the kubeconfig into the file at path.
+func WriteKubeConfig(path string, config KubeConfig) error {
+	content, err := yaml.Marshal(config)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, content, 0600)
+}
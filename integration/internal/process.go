@@ -26,6 +26,10 @@ type ProcessState struct {
 	// HealthCheckEndpoint.
 	// If left empty it will default to 100 Milliseconds.
 	HealthCheckPollInterval time.Duration
+	// HealthCheckClient is the client used to poll the HealthCheckEndpoint,
+	// e.g. one trusting the CA of a process serving it over https.
+	// If left empty it will default to http.DefaultClient.
+	HealthCheckClient *http.Client
 	// StartMessage is the message to wait for on stderr. If we recieve this
 	// message, we assume the process is ready to operate. Ignored if
 	// HealthCheckEndpoint is specified.
@@ -36,6 +40,9 @@ type ProcessState struct {
 	// Deprecated: Use HealthCheckEndpoint in favour of StartMessage
 	StartMessage string
 	Args         []string
+	// Env are environment variables, e.g. "KUBECONFIG=/some/kubeconfig",
+	// the process is started with in addition to those of the test process.
+	Env []string
 }
 
 type DefaultedProcessInput struct {
@@ -63,15 +70,11 @@ func DoDefaulting(
 	}
 
 	if listenUrl == nil {
-		am := &AddressManager{}
-		port, host, err := am.Initialize()
+		localURL, err := NewLocalURL("http")
 		if err != nil {
 			return DefaultedProcessInput{}, err
 		}
-		defaults.URL = url.URL{
-			Scheme: "http",
-			Host:   fmt.Sprintf("%s:%d", host, port),
-		}
+		defaults.URL = *localURL
 	} else {
 		defaults.URL = *listenUrl
 	}
@@ -107,6 +110,9 @@ type stopChannel chan struct{}
 
 func (ps *ProcessState) Start(stdout, stderr io.Writer) (err error) {
 	command := exec.Command(ps.Path, ps.Args...)
+	if len(ps.Env) > 0 {
+		command.Env = append(os.Environ(), ps.Env...)
+	}
 
 	ready := make(chan bool)
 	timedOut := time.After(ps.StartTimeout)
@@ -116,10 +122,10 @@ func (ps *ProcessState) Start(stdout, stderr io.Writer) (err error) {
 		healthCheckURL := ps.URL
 		healthCheckURL.Path = ps.HealthCheckEndpoint
 		pollerStopCh = make(stopChannel)
-		go pollURLUntilOK(healthCheckURL, ps.HealthCheckPollInterval, ready, pollerStopCh)
+		go pollURLUntilOK(ps.HealthCheckClient, healthCheckURL, ps.HealthCheckPollInterval, ready, pollerStopCh)
 	} else {
 		startDetectStream := gbytes.NewBuffer()
-		ready = startDetectStream.Detect(ps.StartMessage)
+		ready = startDetectStream.Detect("%s", ps.StartMessage)
 		stderr = safeMultiWriter(stderr, startDetectStream)
 	}
 
@@ -152,15 +158,21 @@ func safeMultiWriter(writers ...io.Writer) io.Writer {
 	return io.MultiWriter(safeWriters...)
 }
 
-func pollURLUntilOK(url url.URL, interval time.Duration, ready chan bool, stopCh stopChannel) {
+func pollURLUntilOK(client *http.Client, url url.URL, interval time.Duration, ready chan bool, stopCh stopChannel) {
+	if client == nil {
+		client = http.DefaultClient
+	}
 	if interval <= 0 {
 		interval = 100 * time.Millisecond
 	}
 	for {
-		res, err := http.Get(url.String())
-		if err == nil && res.StatusCode == http.StatusOK {
-			ready <- true
-			return
+		res, err := client.Get(url.String())
+		if err == nil {
+			res.Body.Close()
+			if res.StatusCode == http.StatusOK {
+				ready <- true
+				return
+			}
 		}
 
 		select {
@@ -200,3 +212,22 @@ func (ps *ProcessState) Stop() error {
 
 	return nil
 }
+
+// Restart stops the process, keeping its Dir, calls whileStopped, and starts
+// the process again with the same arguments. The process is started again
+// even if whileStopped fails, in which case its error is returned.
+func (ps *ProcessState) Restart(stdout, stderr io.Writer, whileStopped func() error) error {
+	needsCleaning := ps.DirNeedsCleaning
+	ps.DirNeedsCleaning = false
+	err := ps.Stop()
+	ps.DirNeedsCleaning = needsCleaning
+	if err != nil {
+		return err
+	}
+
+	stoppedErr := whileStopped()
+	if err := ps.Start(stdout, stderr); err != nil {
+		return err
+	}
+	return stoppedErr
+}
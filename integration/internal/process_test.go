@@ -154,6 +154,22 @@ var _ = Describe("Start method", func() {
 		})
 	})
 
+	Context("when the health check endpoint is served over https", func() {
+		It("polls it with the configured client", func() {
+			server := ghttp.NewTLSServer()
+			defer server.Close()
+			server.RouteToHandler("GET", "/healthz", ghttp.RespondWith(http.StatusOK, ""))
+
+			processState.HealthCheckEndpoint = "/healthz"
+			processState.HealthCheckClient = server.HTTPTestServer.Client()
+			processState.StartTimeout = 500 * time.Millisecond
+			processState.URL = getServerURL(server)
+
+			Expect(processState.Start(nil, nil)).To(Succeed())
+			Expect(server.ReceivedRequests()).To(HaveLen(1))
+		})
+	})
+
 	Context("when a health check endpoint is not provided", func() {
 
 		Context("when process takes too long to start", func() {
@@ -216,6 +232,21 @@ var _ = Describe("Start method", func() {
 			Expect(stderr.String()).To(Equal("this is stderr\ni started\n"))
 		})
 	})
+
+	Context("when environment variables are configured", func() {
+		It("adds them to the environment of the test process", func() {
+			stdout := &bytes.Buffer{}
+			processState.Args = []string{"-c", `echo "$GREETING $HOME"; echo 'i started' >&2`}
+			processState.Env = []string{"GREETING=hello"}
+			processState.StartMessage = "i started"
+			processState.StartTimeout = 1 * time.Second
+
+			Expect(processState.Start(stdout, nil)).To(Succeed())
+			Eventually(processState.Session).Should(gexec.Exit(0))
+
+			Expect(stdout.String()).To(Equal("hello " + os.Getenv("HOME") + "\n"))
+		})
+	})
 })
 
 var _ = Describe("Stop method", func() {
@@ -279,6 +310,51 @@ var _ = Describe("Stop method", func() {
 	})
 })
 
+var _ = Describe("Restart method", func() {
+	var processState *ProcessState
+
+	BeforeEach(func() {
+		var err error
+		processState = &ProcessState{}
+		processState.Path = "bash"
+		processState.Args = simpleBashScript
+		processState.StartMessage = "loop 1"
+		processState.StartTimeout = 10 * time.Second
+		processState.StopTimeout = 10 * time.Second
+		processState.Dir, err = ioutil.TempDir("", "k8s_test_framework_")
+		Expect(err).NotTo(HaveOccurred())
+		processState.DirNeedsCleaning = true
+
+		Expect(processState.Start(nil, nil)).To(Succeed())
+	})
+	AfterEach(func() {
+		Expect(processState.Stop()).To(Succeed())
+	})
+
+	It("calls the function while the process is stopped, and keeps the directory", func() {
+		firstSession := processState.Session
+
+		Expect(processState.Restart(nil, nil, func() error {
+			Expect(firstSession.ExitCode()).NotTo(Equal(-1))
+			Expect(processState.Dir).To(BeADirectory())
+			return nil
+		})).To(Succeed())
+
+		Expect(processState.Session).NotTo(BeIdenticalTo(firstSession))
+		Expect(processState.Session.ExitCode()).To(Equal(-1))
+		Expect(processState.Dir).To(BeADirectory())
+		Expect(processState.DirNeedsCleaning).To(BeTrue())
+	})
+
+	It("starts the process again even if the function fails", func() {
+		err := processState.Restart(nil, nil, func() error {
+			return fmt.Errorf("some error")
+		})
+		Expect(err).To(MatchError("some error"))
+		Expect(processState.Session.ExitCode()).To(Equal(-1))
+	})
+})
+
 var _ = Describe("DoDefaulting", func() {
 	Context("when all inputs are provided", func() {
 		It("passes them through", func() {
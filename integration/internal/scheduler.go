@@ -0,0 +1,66 @@
+package internal
+
+import (
+	"bytes"
+	"text/template"
+)
+
+// File names of the serving certificate and the configuration of the
+// Scheduler, relative to its CertDir.
+const (
+	SchedulerServingCertFile = "scheduler.crt"
+	SchedulerServingKeyFile  = "scheduler.key"
+	SchedulerConfigFile      = "scheduler-config.yaml"
+)
+
+var SchedulerDefaultArgs = []string{
+	"--config={{ .ConfigFile }}",
+	"--authentication-kubeconfig={{ .KubeConfigFile }}",
+	"--authorization-kubeconfig={{ .KubeConfigFile }}",
+	"--port=0",
+	"--secure-port={{ if .SecureURL }}{{ .SecureURL.Port }}{{ end }}",
+	"--bind-address={{ if .SecureURL }}{{ .SecureURL.Hostname }}{{ end }}",
+	"--cert-dir={{ .CertDir }}",
+	"--tls-cert-file={{ .CertDir }}/" + SchedulerServingCertFile,
+	"--tls-private-key-file={{ .CertDir }}/" + SchedulerServingKeyFile,
+}
+
+// SchedulerDefaultConfig is the minimal configuration to run the Scheduler.
+var SchedulerDefaultConfig = `apiVersion: kubescheduler.config.k8s.io/v1alpha1
+kind: KubeSchedulerConfiguration
+clientConnection:
+  kubeconfig: {{ .KubeConfigFile }}
+leaderElection:
+  leaderElect: false
+`
+
+func DoSchedulerArgDefaulting(args []string) []string {
+	if len(args) != 0 {
+		return args
+	}
+
+	return SchedulerDefaultArgs
+}
+
+func DoSchedulerConfigDefaulting(config string) string {
+	if config != "" {
+		return config
+	}
+
+	return SchedulerDefaultConfig
+}
+
+// RenderConfig evaluates the configuration file as a go-template. Unlike
+// arguments, it is not HTML-escaped.
+func RenderConfig(config string, data interface{}) ([]byte, error) {
+	t, err := template.New("config").Parse(config)
+	if err != nil {
+		return nil, err
+	}
+
+	buf := &bytes.Buffer{}
+	if err := t.Execute(buf, data); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
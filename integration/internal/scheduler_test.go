@@ -0,0 +1,34 @@
+package internal_test
+
+import (
+	. "github.com/kubernetes-sigs/testing_frameworks/integration/internal"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Scheduler", func() {
+	It("defaults Args if they are empty", func() {
+		initialArgs := []string{}
+		defaultedArgs := DoSchedulerArgDefaulting(initialArgs)
+		Expect(defaultedArgs).To(BeEquivalentTo(SchedulerDefaultArgs))
+	})
+
+	It("keeps Args as is if they are not empty", func() {
+		initialArgs := []string{"--one", "--two=2"}
+		defaultedArgs := DoSchedulerArgDefaulting(initialArgs)
+		Expect(defaultedArgs).To(BeEquivalentTo([]string{
+			"--one", "--two=2",
+		}))
+	})
+
+	It("defaults the Config if it is empty", func() {
+		Expect(DoSchedulerConfigDefaulting("")).To(Equal(SchedulerDefaultConfig))
+		Expect(DoSchedulerConfigDefaulting("kind: Custom")).To(Equal("kind: Custom"))
+	})
+
+	It("renders the Config without escaping it", func() {
+		config, err := RenderConfig(`kubeconfig: "{{ .Path }}"`, struct{ Path string }{"/a&b/kubeconfig"})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(config)).To(Equal(`kubeconfig: "/a&b/kubeconfig"`))
+	})
+})
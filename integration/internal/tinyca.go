@@ -0,0 +1,157 @@
+package internal
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net"
+	"sync"
+	"time"
+)
+
+// certValidity is how long the certificates issued by a TinyCA are valid.
+// Test control planes are short lived, a day is plenty.
+const certValidity = 24 * time.Hour
+
+// CertPair is a private key and the certificate issued for it.
+type CertPair struct {
+	Key  crypto.Signer
+	Cert *x509.Certificate
+}
+
+// AsBytes returns the PEM encoded certificate and private key of this pair.
+func (p CertPair) AsBytes() (cert []byte, key []byte, err error) {
+	cert = pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: p.Cert.Raw})
+
+	ecKey, ok := p.Key.(*ecdsa.PrivateKey)
+	if !ok {
+		return nil, nil, fmt.Errorf("unsupported private key type %T", p.Key)
+	}
+	rawKey, err := x509.MarshalECPrivateKey(ecKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	key = pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: rawKey})
+
+	return cert, key, nil
+}
+
+// TinyCA is a minimal certificate authority, which can issue serving and
+// client certificates. It is intended to be used for tests only.
+type TinyCA struct {
+	CA CertPair
+
+	mu         sync.Mutex
+	nextSerial *big.Int
+}
+
+// NewTinyCA creates a new certificate authority with a freshly generated,
+// self-signed certificate.
+func NewTinyCA(name string) (*TinyCA, error) {
+	key, err := newPrivateKey()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: name},
+		NotBefore:             now.Add(-1 * time.Hour),
+		NotAfter:              now.Add(certValidity),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+
+	raw, err := x509.CreateCertificate(rand.Reader, template, template, key.Public(), key)
+	if err != nil {
+		return nil, err
+	}
+	cert, err := x509.ParseCertificate(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	return &TinyCA{
+		CA:         CertPair{Key: key, Cert: cert},
+		nextSerial: big.NewInt(2),
+	}, nil
+}
+
+// CertBytes returns the PEM encoded certificate of the CA.
+func (c *TinyCA) CertBytes() []byte {
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: c.CA.Cert.Raw})
+}
+
+// NewServingCert issues a certificate a server can use to serve TLS for the
+// given names. Names which parse as IP addresses are added as IP SANs, all
+// others as DNS SANs.
+func (c *TinyCA) NewServingCert(names ...string) (CertPair, error) {
+	template := &x509.Certificate{
+		KeyUsage:    x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	if len(names) > 0 {
+		template.Subject = pkix.Name{CommonName: names[0]}
+	}
+	for _, name := range names {
+		if ip := net.ParseIP(name); ip != nil {
+			template.IPAddresses = append(template.IPAddresses, ip)
+		} else {
+			template.DNSNames = append(template.DNSNames, name)
+		}
+	}
+
+	return c.issue(template)
+}
+
+// NewClientCert issues a certificate a client can use to authenticate itself.
+// The kubernetes APIServer interprets the CommonName as the user's name and
+// the Organizations as the user's groups.
+func (c *TinyCA) NewClientCert(name string, groups ...string) (CertPair, error) {
+	template := &x509.Certificate{
+		Subject:     pkix.Name{CommonName: name, Organization: groups},
+		KeyUsage:    x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+
+	return c.issue(template)
+}
+
+func (c *TinyCA) issue(template *x509.Certificate) (CertPair, error) {
+	key, err := newPrivateKey()
+	if err != nil {
+		return CertPair{}, err
+	}
+
+	c.mu.Lock()
+	template.SerialNumber = new(big.Int).Set(c.nextSerial)
+	c.nextSerial.Add(c.nextSerial, big.NewInt(1))
+	c.mu.Unlock()
+
+	now := time.Now()
+	template.NotBefore = now.Add(-1 * time.Hour)
+	template.NotAfter = now.Add(certValidity)
+
+	raw, err := x509.CreateCertificate(rand.Reader, template, c.CA.Cert, key.Public(), c.CA.Key)
+	if err != nil {
+		return CertPair{}, err
+	}
+	cert, err := x509.ParseCertificate(raw)
+	if err != nil {
+		return CertPair{}, err
+	}
+
+	return CertPair{Key: key, Cert: cert}, nil
+}
+
+func newPrivateKey() (*ecdsa.PrivateKey, error) {
+	return ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+}
@@ -0,0 +1,97 @@
+package internal_test
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	. "github.com/kubernetes-sigs/testing_frameworks/integration/internal"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("TinyCA", func() {
+	var ca *TinyCA
+	BeforeEach(func() {
+		var err error
+		ca, err = NewTinyCA("some-ca")
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	verify := func(pair CertPair, usage x509.ExtKeyUsage) {
+		roots := x509.NewCertPool()
+		roots.AddCert(ca.CA.Cert)
+		_, err := pair.Cert.Verify(x509.VerifyOptions{
+			Roots:     roots,
+			KeyUsages: []x509.ExtKeyUsage{usage},
+		})
+		ExpectWithOffset(1, err).NotTo(HaveOccurred())
+	}
+
+	It("issues serving certificates for DNS names and IPs", func() {
+		pair, err := ca.NewServingCert("127.0.0.1", "localhost")
+		Expect(err).NotTo(HaveOccurred())
+
+		verify(pair, x509.ExtKeyUsageServerAuth)
+		Expect(pair.Cert.VerifyHostname("localhost")).To(Succeed())
+		Expect(pair.Cert.VerifyHostname("127.0.0.1")).To(Succeed())
+		Expect(pair.Cert.VerifyHostname("some.other.host")).NotTo(Succeed())
+	})
+
+	It("issues client certificates carrying the user's name and groups", func() {
+		pair, err := ca.NewClientCert("jane", "devs", "admins")
+		Expect(err).NotTo(HaveOccurred())
+
+		verify(pair, x509.ExtKeyUsageClientAuth)
+		Expect(pair.Cert.Subject.CommonName).To(Equal("jane"))
+		Expect(pair.Cert.Subject.Organization).To(ConsistOf("devs", "admins"))
+	})
+
+	It("uses a new serial number for every certificate", func() {
+		one, err := ca.NewClientCert("one")
+		Expect(err).NotTo(HaveOccurred())
+		two, err := ca.NewClientCert("two")
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(one.Cert.SerialNumber).NotTo(Equal(two.Cert.SerialNumber))
+		Expect(one.Cert.SerialNumber).NotTo(Equal(ca.CA.Cert.SerialNumber))
+	})
+
+	It("encodes certificates and keys as PEM", func() {
+		pair, err := ca.NewClientCert("jane")
+		Expect(err).NotTo(HaveOccurred())
+
+		cert, key, err := pair.AsBytes()
+		Expect(err).NotTo(HaveOccurred())
+
+		certBlock, _ := pem.Decode(cert)
+		Expect(certBlock.Type).To(Equal("CERTIFICATE"))
+		keyBlock, _ := pem.Decode(key)
+		Expect(keyBlock.Type).To(Equal("EC PRIVATE KEY"))
+		caBlock, _ := pem.Decode(ca.CertBytes())
+		Expect(caBlock.Bytes).To(Equal(ca.CA.Cert.Raw))
+	})
+
+	Describe("WriteAPIServerCerts", func() {
+		var dir string
+		BeforeEach(func() {
+			var err error
+			dir, err = ioutil.TempDir("", "k8s_test_framework_")
+			Expect(err).NotTo(HaveOccurred())
+		})
+		AfterEach(func() {
+			Expect(os.RemoveAll(dir)).To(Succeed())
+		})
+
+		It("writes the CA and the serving certificates", func() {
+			certDir := filepath.Join(dir, "not", "yet", "existing")
+			Expect(WriteAPIServerCerts(certDir, ca, "127.0.0.1")).To(Succeed())
+
+			Expect(filepath.Join(certDir, APIServerCACertFile)).To(BeAnExistingFile())
+			Expect(filepath.Join(certDir, APIServerServingCertFile)).To(BeAnExistingFile())
+			Expect(filepath.Join(certDir, APIServerServingKeyFile)).To(BeAnExistingFile())
+		})
+	})
+})
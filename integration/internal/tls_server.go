@@ -0,0 +1,92 @@
+package internal
+
+import (
+	"crypto/tls"
+	"net"
+	"net/http"
+	"net/url"
+)
+
+// TLSServer serves a handler over TLS on a free port on localhost. Its serving
+// certificate is issued by a CA of its own, so clients need to trust that CA
+// to connect.
+type TLSServer struct {
+	URL *url.URL
+	CA  *TinyCA
+
+	server   *http.Server
+	listener net.Listener
+}
+
+// StartTLSServer starts serving the handler in the background.
+func StartTLSServer(name string, handler http.Handler) (*TLSServer, error) {
+	ca, err := NewTinyCA(name + "-ca")
+	if err != nil {
+		return nil, err
+	}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, err
+	}
+	host, _, err := net.SplitHostPort(listener.Addr().String())
+	if err != nil {
+		listener.Close()
+		return nil, err
+	}
+
+	servingCert, err := ca.NewServingCert(host, "localhost")
+	if err != nil {
+		listener.Close()
+		return nil, err
+	}
+	cert, key, err := servingCert.AsBytes()
+	if err != nil {
+		listener.Close()
+		return nil, err
+	}
+	keyPair, err := tls.X509KeyPair(cert, key)
+	if err != nil {
+		listener.Close()
+		return nil, err
+	}
+
+	s := &TLSServer{
+		URL:      &url.URL{Scheme: "https", Host: listener.Addr().String()},
+		CA:       ca,
+		listener: listener,
+		server: &http.Server{
+			Handler:   handler,
+			TLSConfig: &tls.Config{Certificates: []tls.Certificate{keyPair}},
+		},
+	}
+
+	go s.server.Serve(tls.NewListener(listener, s.server.TLSConfig))
+
+	return s, nil
+}
+
+// Restart serves the handler again after the server has been closed, on the
+// same port and with the same certificate, so clients can keep using the URL
+// and the CA.
+func (s *TLSServer) Restart() error {
+	listener, err := net.Listen("tcp", s.URL.Host)
+	if err != nil {
+		return err
+	}
+
+	s.listener = listener
+	s.server = &http.Server{
+		Handler:   s.server.Handler,
+		TLSConfig: s.server.TLSConfig,
+	}
+
+	go s.server.Serve(tls.NewListener(listener, s.server.TLSConfig))
+
+	return nil
+}
+
+// Close stops the server and closes all its connections.
+func (s *TLSServer) Close() error {
+	return s.server.Close()
+}
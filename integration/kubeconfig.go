@@ -0,0 +1,101 @@
+package integration
+
+import (
+	"encoding/base64"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/kubernetes-sigs/testing_frameworks/integration/internal"
+)
+
+// AdminUser is the user the kubeconfig of a ControlPlane authenticates as. As
+// a member of "system:masters" it is allowed to do anything, regardless of
+// the APIServer's AuthorizationMode.
+var AdminUser = User{Name: "admin", Groups: []string{"system:masters"}}
+
+// kubeConfigName is the name of the cluster, user and context in the
+// kubeconfig of a ControlPlane.
+const kubeConfigName = "integration"
+
+// KubeConfigFile returns the path to a kubeconfig for this ControlPlane. It
+// connects to the secure URL, trusts the APIServer's CA, authenticates as the
+// AdminUser and uses the "default" namespace.
+//
+// The file is written when the ControlPlane starts and rewritten, at the same
+// path, whenever it is started again, e.g. after a restart on new ports. It
+// is removed when the ControlPlane is stopped. The path is empty if the
+// ControlPlane has never been started.
+//
+// Checkpoint and Rollback do not rewrite the file: they restart the APIServer
+// on the same URLs and with the same CertDir, so the server and CA in the
+// kubeconfig stay valid.
+//
+// Point other tools, or client-go based code under test, to it, e.g.:
+//
+//	cmd.Env = append(os.Environ(), "KUBECONFIG="+cp.KubeConfigFile())
+func (f *ControlPlane) KubeConfigFile() string {
+	if f.dir == "" {
+		return ""
+	}
+	return filepath.Join(f.dir, "kubeconfig")
+}
+
+// KubeConfig returns the contents of the file at KubeConfigFile().
+func (f *ControlPlane) KubeConfig() ([]byte, error) {
+	if f.dir == "" {
+		return nil, fmt.Errorf("the ControlPlane needs to be started before it has a kubeconfig")
+	}
+	return ioutil.ReadFile(f.KubeConfigFile())
+}
+
+// writeKubeConfig (re-)writes the kubeconfig for the APIServer's current
+// SecureURL.
+func (f *ControlPlane) writeKubeConfig() error {
+	if f.dir == "" {
+		dir, err := ioutil.TempDir("", "k8s_test_framework_")
+		if err != nil {
+			return err
+		}
+		f.dir = dir
+	} else if err := os.MkdirAll(f.dir, 0700); err != nil {
+		return err
+	}
+
+	return f.writeKubeConfigAs(f.KubeConfigFile(), AdminUser)
+}
+
+// writeKubeConfigAs writes a kubeconfig like the one at KubeConfigFile(),
+// which authenticates as the user, to path.
+func (f *ControlPlane) writeKubeConfigAs(path string, user User) error {
+	cert, key, err := f.APIServer.clientCert(user)
+	if err != nil {
+		return err
+	}
+
+	config := internal.NewKubeConfig(
+		kubeConfigName,
+		internal.Cluster{
+			Server:                   f.SecureAPIURL().String(),
+			CertificateAuthorityData: base64.StdEncoding.EncodeToString(f.APIServer.ca.CertBytes()),
+		},
+		internal.AuthInfo{
+			ClientCertificateData: base64.StdEncoding.EncodeToString(cert),
+			ClientKeyData:         base64.StdEncoding.EncodeToString(key),
+		},
+		"default",
+	)
+	return internal.WriteKubeConfig(path, config)
+}
+
+// removeDir removes the directory of this ControlPlane, f.dir, with everything
+// in it: the kubeconfigs of the ControlPlane and its components, the HOMEs of
+// KubeCtls and the checkpoints. The path is remembered, so the next start
+// writes the kubeconfig to the same path again.
+func (f *ControlPlane) removeDir() error {
+	if f.dir == "" {
+		return nil
+	}
+	return os.RemoveAll(f.dir)
+}
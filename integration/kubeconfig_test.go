@@ -0,0 +1,75 @@
+package integration
+
+import (
+	"net/url"
+	"os"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	yaml "gopkg.in/yaml.v2"
+
+	"github.com/kubernetes-sigs/testing_frameworks/integration/internal"
+)
+
+var _ = Describe("ControlPlane's kubeconfig", func() {
+	var cp *ControlPlane
+
+	BeforeEach(func() {
+		ca, err := internal.NewTinyCA("test-ca")
+		Expect(err).NotTo(HaveOccurred())
+
+		cp = &ControlPlane{
+			APIServer: &APIServer{
+				SecureURL: &url.URL{Scheme: "https", Host: "127.0.0.1:1234"},
+				ca:        ca,
+			},
+		}
+	})
+	AfterEach(func() {
+		Expect(cp.removeDir()).To(Succeed())
+	})
+
+	readConfig := func() internal.KubeConfig {
+		content, err := cp.KubeConfig()
+		ExpectWithOffset(1, err).NotTo(HaveOccurred())
+		config := internal.KubeConfig{}
+		ExpectWithOffset(1, yaml.Unmarshal(content, &config)).To(Succeed())
+		return config
+	}
+
+	It("has no kubeconfig before being started", func() {
+		Expect(cp.KubeConfigFile()).To(BeEmpty())
+		_, err := cp.KubeConfig()
+		Expect(err).To(MatchError(ContainSubstring("needs to be started")))
+	})
+
+	It("writes a complete kubeconfig", func() {
+		Expect(cp.writeKubeConfig()).To(Succeed())
+
+		config := readConfig()
+		Expect(config.CurrentContext).To(Equal("integration"))
+		Expect(config.Contexts).To(HaveLen(1))
+		Expect(config.Contexts[0].Context.Namespace).To(Equal("default"))
+		Expect(config.Clusters).To(HaveLen(1))
+		Expect(config.Clusters[0].Cluster.Server).To(Equal("https://127.0.0.1:1234"))
+		Expect(config.Clusters[0].Cluster.CertificateAuthorityData).NotTo(BeEmpty())
+		Expect(config.Users).To(HaveLen(1))
+		Expect(config.Users[0].User.ClientCertificateData).NotTo(BeEmpty())
+		Expect(config.Users[0].User.ClientKeyData).NotTo(BeEmpty())
+	})
+
+	It("rewrites the kubeconfig at the same path on restarts", func() {
+		Expect(cp.writeKubeConfig()).To(Succeed())
+		path := cp.KubeConfigFile()
+
+		Expect(cp.removeDir()).To(Succeed())
+		_, err := os.Stat(path)
+		Expect(os.IsNotExist(err)).To(BeTrue())
+
+		cp.APIServer.SecureURL = &url.URL{Scheme: "https", Host: "127.0.0.1:5678"}
+		Expect(cp.writeKubeConfig()).To(Succeed())
+
+		Expect(cp.KubeConfigFile()).To(Equal(path))
+		Expect(readConfig().Clusters[0].Cluster.Server).To(Equal("https://127.0.0.1:5678"))
+	})
+})
@@ -2,8 +2,16 @@ package integration
 
 import (
 	"bytes"
+	"context"
+	"fmt"
 	"io"
+	"io/ioutil"
+	"os"
 	"os/exec"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
 
 	"github.com/kubernetes-sigs/testing_frameworks/integration/internal"
 )
@@ -23,25 +31,157 @@ type KubeCtl struct {
 	// For example, you might want to use this to set the URL of the APIServer to
 	// connect to.
 	Opts []string
+
+	// Env are additional environment variables, in the form "key=value", to run
+	// the wrapped binary with. See Run for the environment it is run with by
+	// default.
+	Env []string
+
+	// Dir is the directory used as HOME, and thus for kubectl's cache, when
+	// running the wrapped binary. The default KUBECONFIG points to a
+	// non-existent file in it.
+	//
+	// If this is left empty, a fresh temporary directory is used for every
+	// command and removed afterwards.
+	Dir string
+
+	// Timeout specifies the time after which a command is killed.
+	//
+	// If not specified, commands are not killed.
+	Timeout time.Duration
 }
 
 // Run executes the wrapped binary with some preconfigured options and the
 // arguments given to this method. It returns Readers for the stdout and
 // stderr.
+//
+// Apart from PATH, the binary does not inherit the environment of the test
+// process: HOME is set to Dir and KUBECONFIG to a file in there, so neither a
+// developer's KUBECONFIG nor their ~/.kube directory affect the tests. Env is
+// added on top of that.
+//
+// If the command fails, the error is a *KubeCtlError.
 func (k *KubeCtl) Run(args ...string) (stdout, stderr io.Reader, err error) {
+	return k.run(context.Background(), nil, args)
+}
+
+// RunContext executes the wrapped binary like Run does. The command is killed
+// if the context is done before it exits.
+func (k *KubeCtl) RunContext(ctx context.Context, args ...string) (stdout, stderr io.Reader, err error) {
+	return k.run(ctx, nil, args)
+}
+
+// RunWithStdin executes the wrapped binary like Run does, and pipes stdin to
+// it, e.g. to apply a manifest:
+//
+//	kubeCtl.RunWithStdin(strings.NewReader(manifest), "apply", "--filename", "-")
+func (k *KubeCtl) RunWithStdin(stdin io.Reader, args ...string) (stdout, stderr io.Reader, err error) {
+	return k.run(context.Background(), stdin, args)
+}
+
+func (k *KubeCtl) run(ctx context.Context, stdin io.Reader, args []string) (stdout, stderr io.Reader, err error) {
 	if k.Path == "" {
 		k.Path = internal.BinPathFinder("kubectl")
 	}
 
+	if k.Timeout != 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, k.Timeout)
+		defer cancel()
+	}
+
+	env, cleanup, err := k.environment()
+	if err != nil {
+		return nil, nil, err
+	}
+	defer cleanup()
+
 	stdoutBuffer := &bytes.Buffer{}
 	stderrBuffer := &bytes.Buffer{}
-	allArgs := append(k.Opts, args...)
+	allArgs := append(append([]string{}, k.Opts...), args...)
 
-	cmd := exec.Command(k.Path, allArgs...)
+	cmd := exec.CommandContext(ctx, k.Path, allArgs...)
+	cmd.Env = env
+	cmd.Stdin = stdin
 	cmd.Stdout = stdoutBuffer
 	cmd.Stderr = stderrBuffer
 
-	err = cmd.Run()
+	if err := cmd.Run(); err != nil {
+		return stdoutBuffer, stderrBuffer, newKubeCtlError(ctx, args, stderrBuffer.String(), err)
+	}
+
+	return stdoutBuffer, stderrBuffer, nil
+}
+
+// environment returns the environment kubectl is run with, and a function to
+// clean up the temporary HOME, if one was needed.
+func (k *KubeCtl) environment() ([]string, func(), error) {
+	dir := k.Dir
+	cleanup := func() {}
+	if dir == "" {
+		var err error
+		dir, err = ioutil.TempDir("", "k8s_test_framework_")
+		if err != nil {
+			return nil, nil, err
+		}
+		cleanup = func() { os.RemoveAll(dir) }
+	} else if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, nil, err
+	}
+
+	env := []string{
+		"PATH=" + os.Getenv("PATH"),
+		"HOME=" + dir,
+		"KUBECONFIG=" + filepath.Join(dir, ".kube", "config"),
+	}
+	return append(env, k.Env...), cleanup, nil
+}
+
+// KubeCtlError is returned when kubectl could not be run or exited
+// unsuccessfully.
+type KubeCtlError struct {
+	// Args are the arguments kubectl was run with, without the KubeCtl's Opts.
+	Args []string
+
+	// ExitCode is the code kubectl exited with, or -1 if it did not exit on its
+	// own, e.g. because it could not be started or was killed.
+	ExitCode int
+
+	// Stderr is what kubectl wrote to its stderr.
+	Stderr string
+
+	// Err is the underlying error.
+	Err error
+}
 
-	return stdoutBuffer, stderrBuffer, err
+func newKubeCtlError(ctx context.Context, args []string, stderr string, err error) *KubeCtlError {
+	kubeCtlErr := &KubeCtlError{
+		Args:     args,
+		ExitCode: -1,
+		Stderr:   strings.TrimSpace(stderr),
+		Err:      err,
+	}
+	if exitErr, ok := err.(*exec.ExitError); ok {
+		if status, ok := exitErr.Sys().(syscall.WaitStatus); ok && status.Exited() {
+			kubeCtlErr.ExitCode = status.ExitStatus()
+		}
+	}
+	if ctx.Err() != nil {
+		kubeCtlErr.Err = fmt.Errorf("%s (%s)", err, ctx.Err())
+	}
+	return kubeCtlErr
+}
+
+func (e *KubeCtlError) Error() string {
+	return fmt.Sprintf("kubectl %s failed: %s: %s", strings.Join(e.Args, " "), e.Err, e.Stderr)
+}
+
+// runForOutput runs kubectl like Run does, but returns the stdout as a string.
+func (k *KubeCtl) runForOutput(args ...string) (string, error) {
+	stdout, _, err := k.Run(args...)
+	if err != nil {
+		return "", err
+	}
+	out, err := ioutil.ReadAll(stdout)
+	return string(out), err
 }
@@ -0,0 +1,66 @@
+package integration
+
+import (
+	"io"
+	"os/exec"
+
+	"github.com/onsi/gomega/gexec"
+
+	"github.com/kubernetes-sigs/testing_frameworks/integration/internal"
+)
+
+// KubeCtlSession is a kubectl command started by KubeCtl.Start, which might
+// still be running.
+//
+// It embeds a gexec.Session, so its output is available in the Out and Err
+// buffers while the command is still running, it can be signalled and waited
+// for, and it works with gbytes matchers, e.g.:
+//
+//	session, err := kubeCtl.Start("get", "configmaps", "--watch")
+//	Expect(err).NotTo(HaveOccurred())
+//	defer session.Terminate()
+//	Eventually(session).Should(gbytes.Say("my-configmap"))
+type KubeCtlSession struct {
+	*gexec.Session
+
+	// Stdin is piped to the command. Close it to signal the end of the input.
+	Stdin io.WriteCloser
+}
+
+// Start starts the wrapped binary with the preconfigured options and the
+// arguments given to this method, in the same environment Run uses, and
+// returns without waiting for it to exit. The KubeCtl's Timeout does not
+// apply, use the session's Wait, Terminate or Kill methods instead.
+func (k *KubeCtl) Start(args ...string) (*KubeCtlSession, error) {
+	if k.Path == "" {
+		k.Path = internal.BinPathFinder("kubectl")
+	}
+
+	env, cleanup, err := k.environment()
+	if err != nil {
+		return nil, err
+	}
+
+	allArgs := append(append([]string{}, k.Opts...), args...)
+	cmd := exec.Command(k.Path, allArgs...)
+	cmd.Env = env
+
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		cleanup()
+		return nil, err
+	}
+
+	session, err := gexec.Start(cmd, nil, nil)
+	if err != nil {
+		cleanup()
+		return nil, err
+	}
+
+	go func() {
+		<-session.Exited
+		cleanup()
+	}()
+
+	return &KubeCtlSession{Session: session, Stdin: stdin}, nil
+}
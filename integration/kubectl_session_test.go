@@ -0,0 +1,64 @@
+package integration_test
+
+import (
+	"os"
+	"strings"
+	"syscall"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
+	"github.com/onsi/gomega/gexec"
+
+	. "github.com/kubernetes-sigs/testing_frameworks/integration"
+)
+
+var _ = Describe("KubeCtl sessions", func() {
+	It("streams the output while the command is running", func() {
+		k := &KubeCtl{Path: "bash"}
+		session, err := k.Start("-c", "echo 'first'; echo 'on stderr' >&2; sleep 10")
+		Expect(err).NotTo(HaveOccurred())
+		defer session.Kill()
+
+		Eventually(session).Should(gbytes.Say("first"))
+		Eventually(session.Err).Should(gbytes.Say("on stderr"))
+		Consistently(session.Exited, "100ms").ShouldNot(BeClosed())
+	})
+
+	It("pipes stdin to the command", func() {
+		k := &KubeCtl{Path: "bash"}
+		session, err := k.Start("-c", "read line; echo \"got $line\"")
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = session.Stdin.Write([]byte("some input\n"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(session.Stdin.Close()).To(Succeed())
+
+		Eventually(session).Should(gexec.Exit(0))
+		Expect(session).To(gbytes.Say("got some input"))
+	})
+
+	It("can be signalled", func() {
+		k := &KubeCtl{Path: "bash"}
+		session, err := k.Start("-c", "sleep 10")
+		Expect(err).NotTo(HaveOccurred())
+
+		session.Signal(syscall.SIGTERM)
+		Eventually(session).Should(gexec.Exit())
+		Expect(session.ExitCode()).NotTo(Equal(0))
+	})
+
+	It("cleans up the temporary HOME after the command exits", func() {
+		k := &KubeCtl{Path: "bash"}
+		session, err := k.Start("-c", "echo \"$HOME\"")
+		Expect(err).NotTo(HaveOccurred())
+		Eventually(session).Should(gexec.Exit(0))
+
+		home := strings.TrimSpace(string(session.Out.Contents()))
+		Expect(home).NotTo(BeEmpty())
+		Eventually(func() bool {
+			_, err := os.Stat(home)
+			return os.IsNotExist(err)
+		}).Should(BeTrue())
+	})
+})
@@ -1,7 +1,11 @@
 package integration_test
 
 import (
+	"context"
 	"io/ioutil"
+	"os"
+	"strings"
+	"time"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
@@ -36,4 +40,74 @@ var _ = Describe("Kubectl", func() {
 			Expect(stderr).To(ContainSubstring("this is StdErr"))
 		})
 	})
+
+	Context("when the command returns a non-zero exit code", func() {
+		It("returns a KubeCtlError with the exit code and stderr", func() {
+			k := &KubeCtl{Path: "bash"}
+			_, _, err := k.Run("-c", "echo 'this is StdErr' >&2; exit 66")
+
+			kubeCtlErr, ok := err.(*KubeCtlError)
+			Expect(ok).To(BeTrue())
+			Expect(kubeCtlErr.ExitCode).To(Equal(66))
+			Expect(kubeCtlErr.Stderr).To(Equal("this is StdErr"))
+			Expect(kubeCtlErr.Args).To(ConsistOf("-c", "echo 'this is StdErr' >&2; exit 66"))
+		})
+	})
+
+	It("pipes stdin to the command", func() {
+		k := &KubeCtl{Path: "bash"}
+		stdout, _, err := k.RunWithStdin(strings.NewReader("some manifest"), "-c", "cat")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(stdout).To(ContainSubstring("some manifest"))
+	})
+
+	It("kills the command when the context is done", func() {
+		k := &KubeCtl{Path: "bash"}
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+
+		_, _, err := k.RunContext(ctx, "-c", "sleep 10")
+		Expect(err).To(MatchError(ContainSubstring("context deadline exceeded")))
+		Expect(err.(*KubeCtlError).ExitCode).To(Equal(-1))
+	})
+
+	It("kills the command after the Timeout", func() {
+		k := &KubeCtl{Path: "bash", Timeout: 100 * time.Millisecond}
+		_, _, err := k.Run("-c", "sleep 10")
+		Expect(err).To(HaveOccurred())
+	})
+
+	Describe("the environment", func() {
+		BeforeEach(func() {
+			os.Setenv("KUBECTL_TEST_LEAKED", "leaked")
+		})
+		AfterEach(func() {
+			os.Unsetenv("KUBECTL_TEST_LEAKED")
+		})
+
+		It("does not inherit the environment apart from PATH", func() {
+			k := &KubeCtl{Path: "bash", Env: []string{"SOME_VAR=some-value"}}
+			stdout, _, err := k.Run("-c", "echo \"$KUBECTL_TEST_LEAKED,$SOME_VAR\"")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(stdout).To(ContainSubstring(",some-value"))
+		})
+
+		It("uses an isolated HOME and KUBECONFIG", func() {
+			dir, err := ioutil.TempDir("", "k8s_test_framework_")
+			Expect(err).NotTo(HaveOccurred())
+			defer os.RemoveAll(dir)
+
+			k := &KubeCtl{Path: "bash", Dir: dir}
+			stdout, _, err := k.Run("-c", "echo \"$HOME $KUBECONFIG\"")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(stdout).To(ContainSubstring(dir + " " + dir + "/.kube/config"))
+		})
+
+		It("lets Env override the defaults", func() {
+			k := &KubeCtl{Path: "bash", Env: []string{"KUBECONFIG=/some/kubeconfig"}}
+			stdout, _, err := k.Run("-c", "echo \"$KUBECONFIG\"")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(stdout).To(ContainSubstring("/some/kubeconfig"))
+		})
+	})
 })
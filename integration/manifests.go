@@ -0,0 +1,361 @@
+package integration
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+// crdEstablishTimeout is the time CustomResourceDefinitions are given to
+// become established, before their instances are applied.
+var crdEstablishTimeout = 20 * time.Second
+
+// AppliedManifests are the objects ControlPlane.ApplyManifests created or
+// updated.
+type AppliedManifests struct {
+	// Objects are the applied objects, in the order they have been applied.
+	Objects []Object
+
+	kubeCtl *KubeCtl
+	// created are the objects which did not exist before, by phase.
+	created [][]Object
+}
+
+// ApplyManifests applies the manifests in the files at the paths, with
+// `kubectl apply`. Paths to directories are walked recursively for files
+// ending in ".yaml", ".yml" or ".json". See ApplyManifestData for details.
+func (f *ControlPlane) ApplyManifests(paths ...string) (*AppliedManifests, error) {
+	manifests := [][]byte{}
+	for _, path := range paths {
+		err := filepath.Walk(path, func(file string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			if info.IsDir() || !isManifestFile(file) {
+				return nil
+			}
+			content, err := ioutil.ReadFile(file)
+			if err != nil {
+				return err
+			}
+			manifests = append(manifests, content)
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return f.ApplyManifestData(manifests...)
+}
+
+// ApplyManifestData applies the manifests, with `kubectl apply`. Each
+// manifest can be JSON or YAML, with multiple documents separated by "---",
+// and might contain Lists.
+//
+// Namespaces and CustomResourceDefinitions are applied first, and the
+// CustomResourceDefinitions are waited for to be established, so the
+// manifests can contain instances of them. Then all other objects are
+// applied.
+//
+// Call Delete on the returned AppliedManifests to delete the objects it
+// created again, even if applying failed half way, e.g.:
+//
+//	fixtures, err := cp.ApplyManifests("testdata/fixtures")
+//	Expect(err).NotTo(HaveOccurred())
+//	defer fixtures.Delete()
+func (f *ControlPlane) ApplyManifestData(manifests ...[]byte) (*AppliedManifests, error) {
+	objects := []Object{}
+	for _, manifest := range manifests {
+		decoded, err := decodeManifest(manifest)
+		if err != nil {
+			return nil, err
+		}
+		objects = append(objects, decoded...)
+	}
+
+	applied := &AppliedManifests{
+		Objects: []Object{},
+		kubeCtl: f.KubeCtl(),
+	}
+
+	for i, phase := range orderManifests(objects) {
+		created, err := applied.kubeCtl.applyObjects(phase)
+		applied.created = append(applied.created, created)
+		if err != nil {
+			return applied, err
+		}
+		applied.Objects = append(applied.Objects, phase...)
+
+		if i == 0 {
+			if err := applied.kubeCtl.waitForCRDs(phase); err != nil {
+				return applied, err
+			}
+		}
+	}
+
+	return applied, nil
+}
+
+// Delete deletes the applied objects which did not exist before, in the
+// reverse order they have been applied in. Objects which already existed,
+// e.g. the "default" Namespace, are kept, even though they might have been
+// changed. It does not wait for the objects to be gone, as there is no
+// namespace controller to finalize Namespaces.
+func (m *AppliedManifests) Delete() error {
+	for i := len(m.created) - 1; i >= 0; i-- {
+		if len(m.created[i]) == 0 {
+			continue
+		}
+		list, err := listOf(m.created[i])
+		if err != nil {
+			return err
+		}
+		_, _, err = m.kubeCtl.RunWithStdin(bytes.NewReader(list),
+			"delete", "--filename", "-", "--ignore-not-found", "--wait=false",
+		)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func isManifestFile(path string) bool {
+	switch filepath.Ext(path) {
+	case ".yaml", ".yml", ".json":
+		return true
+	}
+	return false
+}
+
+// decodeManifest decodes all documents in the manifest, and flattens Lists.
+func decodeManifest(manifest []byte) ([]Object, error) {
+	objects := []Object{}
+	for _, document := range splitYAMLDocuments(manifest) {
+		var raw interface{}
+		if err := yaml.Unmarshal(document, &raw); err != nil {
+			return nil, fmt.Errorf("could not decode manifest: %s", err)
+		}
+		if raw == nil {
+			continue
+		}
+
+		object, ok := jsonCompatible(raw).(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("expected a manifest to contain objects, got:\n%s", document)
+		}
+		if Object(object).Kind() == "" {
+			return nil, fmt.Errorf("expected a manifest to contain objects with a kind, got:\n%s", document)
+		}
+
+		if strings.HasSuffix(Object(object).Kind(), "List") {
+			objects = append(objects, Object(object).Items()...)
+		} else {
+			objects = append(objects, object)
+		}
+	}
+	return objects, nil
+}
+
+// splitYAMLDocuments splits a YAML stream into its documents, as the vendored
+// yaml package can only decode single documents.
+func splitYAMLDocuments(stream []byte) [][]byte {
+	documents := [][]byte{}
+	current := &bytes.Buffer{}
+
+	scanner := bufio.NewScanner(bytes.NewReader(stream))
+	scanner.Buffer(make([]byte, 64*1024), len(stream)+1)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if isDocumentSeparator(line) {
+			documents = append(documents, current.Bytes())
+			current = &bytes.Buffer{}
+			continue
+		}
+		current.WriteString(line)
+		current.WriteString("\n")
+	}
+	return append(documents, current.Bytes())
+}
+
+func isDocumentSeparator(line string) bool {
+	if !strings.HasPrefix(line, "---") {
+		return false
+	}
+	rest := strings.TrimSpace(line[3:])
+	return rest == "" || strings.HasPrefix(rest, "#")
+}
+
+// jsonCompatible converts the maps the yaml package decodes into, which are
+// keyed by interface{}, into maps keyed by string.
+func jsonCompatible(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[interface{}]interface{}:
+		m := map[string]interface{}{}
+		for key, val := range v {
+			m[fmt.Sprintf("%v", key)] = jsonCompatible(val)
+		}
+		return m
+	case []interface{}:
+		s := make([]interface{}, len(v))
+		for i, val := range v {
+			s[i] = jsonCompatible(val)
+		}
+		return s
+	}
+	return value
+}
+
+// orderManifests splits the objects into the phases they are applied in:
+// first Namespaces and CustomResourceDefinitions, then everything else.
+func orderManifests(objects []Object) [][]Object {
+	first, rest := []Object{}, []Object{}
+	for _, object := range objects {
+		switch object.Kind() {
+		case "Namespace", "CustomResourceDefinition":
+			first = append(first, object)
+		default:
+			rest = append(rest, object)
+		}
+	}
+	return [][]Object{first, rest}
+}
+
+func listOf(objects []Object) ([]byte, error) {
+	items := make([]interface{}, len(objects))
+	for i, object := range objects {
+		items[i] = map[string]interface{}(object)
+	}
+	return json.Marshal(map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "List",
+		"items":      items,
+	})
+}
+
+// applyObjects applies the objects, and returns the ones which did not exist
+// before, and have therefore been created rather than changed. They are
+// returned even if applying fails, as kubectl might have created some of them
+// before it failed.
+func (k *KubeCtl) applyObjects(objects []Object) ([]Object, error) {
+	if len(objects) == 0 {
+		return nil, nil
+	}
+	list, err := listOf(objects)
+	if err != nil {
+		return nil, err
+	}
+	existing, err := k.getObjects(list)
+	if err != nil {
+		return nil, err
+	}
+
+	created := []Object{}
+	for _, object := range objects {
+		if !containsObject(existing, object) {
+			created = append(created, object)
+		}
+	}
+
+	_, _, err = k.RunWithStdin(bytes.NewReader(list), "apply", "--filename", "-")
+	return created, err
+}
+
+// getObjects returns those of the objects in the list which exist.
+func (k *KubeCtl) getObjects(list []byte) ([]Object, error) {
+	stdout, _, err := k.RunWithStdin(bytes.NewReader(list),
+		"get", "--filename", "-", "--ignore-not-found", "--output", "json",
+	)
+	if err != nil {
+		return nil, err
+	}
+	output, err := ioutil.ReadAll(stdout)
+	if err != nil {
+		return nil, err
+	}
+	if len(bytes.TrimSpace(output)) == 0 {
+		// Depending on its version, kubectl prints nothing if none exist.
+		return nil, nil
+	}
+
+	found := Object{}
+	if err := json.Unmarshal(output, &found); err != nil {
+		return nil, fmt.Errorf("could not decode the output of kubectl get: %s", err)
+	}
+	if strings.HasSuffix(found.Kind(), "List") {
+		return found.Items(), nil
+	}
+	return []Object{found}, nil
+}
+
+// containsObject returns true if the objects contain one of the same group,
+// kind, namespace and name as the object. An object without a namespace is
+// applied to the "default" namespace, if it is namespaced.
+func containsObject(objects []Object, object Object) bool {
+	for _, o := range objects {
+		if apiGroup(o) != apiGroup(object) || o.Kind() != object.Kind() || o.Name() != object.Name() {
+			continue
+		}
+		if o.Namespace() == object.Namespace() || (object.Namespace() == "" && o.Namespace() == "default") {
+			return true
+		}
+	}
+	return false
+}
+
+// apiGroup returns the group of the object's apiVersion.
+func apiGroup(object Object) string {
+	if i := strings.Index(object.APIVersion(), "/"); i >= 0 {
+		return object.APIVersion()[:i]
+	}
+	return ""
+}
+
+// waitForCRDs waits for all CustomResourceDefinitions among the objects to
+// be established.
+func (k *KubeCtl) waitForCRDs(objects []Object) error {
+	timedOut := time.After(crdEstablishTimeout)
+	for _, object := range objects {
+		if object.Kind() != "CustomResourceDefinition" {
+			continue
+		}
+		for {
+			crd, err := k.GetObject("get", "customresourcedefinition", object.Name())
+			if err != nil {
+				return err
+			}
+			if hasCondition(crd, "Established", "True") {
+				break
+			}
+
+			select {
+			case <-timedOut:
+				return fmt.Errorf("timeout waiting for CustomResourceDefinition %s to be established", object.Name())
+			case <-time.After(100 * time.Millisecond):
+			}
+		}
+	}
+	return nil
+}
+
+// hasCondition returns true if the object has a condition of the type with
+// the status in its status.conditions.
+func hasCondition(object Object, conditionType, status string) bool {
+	conditions, _ := object.Field("status.conditions")
+	list, _ := conditions.([]interface{})
+	for _, c := range list {
+		condition, ok := c.(map[string]interface{})
+		if ok && condition["type"] == conditionType && condition["status"] == status {
+			return true
+		}
+	}
+	return false
+}
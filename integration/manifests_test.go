@@ -0,0 +1,130 @@
+package integration
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Manifests", func() {
+	Describe("decoding", func() {
+		It("decodes multi-document YAML", func() {
+			objects, err := decodeManifest([]byte(`
+# a leading comment
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: first
+data:
+  replicas: "3"
+--- # the second document
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: second
+---
+`))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(objects).To(HaveLen(2))
+			Expect(objects[0].Name()).To(Equal("first"))
+			Expect(objects[0]).To(HaveField("data.replicas", "3"))
+			Expect(objects[1].Name()).To(Equal("second"))
+		})
+
+		It("decodes JSON and flattens Lists", func() {
+			objects, err := decodeManifest([]byte(`{
+				"apiVersion": "v1",
+				"kind": "List",
+				"items": [
+					{"apiVersion": "v1", "kind": "Namespace", "metadata": {"name": "first"}},
+					{"apiVersion": "v1", "kind": "Namespace", "metadata": {"name": "second"}}
+				]
+			}`))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(objects).To(HaveLen(2))
+			Expect(objects[1].Kind()).To(Equal("Namespace"))
+			Expect(objects[1].Name()).To(Equal("second"))
+		})
+
+		It("fails on documents which are not objects", func() {
+			_, err := decodeManifest([]byte("- just\n- a list\n"))
+			Expect(err).To(MatchError(ContainSubstring("expected a manifest to contain objects")))
+
+			_, err = decodeManifest([]byte("metadata:\n  name: no-kind\n"))
+			Expect(err).To(MatchError(ContainSubstring("with a kind")))
+
+			_, err = decodeManifest([]byte("kind: [unterminated\n"))
+			Expect(err).To(MatchError(ContainSubstring("could not decode manifest")))
+		})
+	})
+
+	It("orders Namespaces and CustomResourceDefinitions first", func() {
+		objects := []Object{
+			{"kind": "Foo", "metadata": map[string]interface{}{"name": "my-foo"}},
+			{"kind": "CustomResourceDefinition", "metadata": map[string]interface{}{"name": "foos.example.com"}},
+			{"kind": "ConfigMap", "metadata": map[string]interface{}{"name": "my-config"}},
+			{"kind": "Namespace", "metadata": map[string]interface{}{"name": "my-ns"}},
+		}
+
+		phases := orderManifests(objects)
+		Expect(phases).To(HaveLen(2))
+		Expect(phases[0]).To(Equal([]Object{objects[1], objects[3]}))
+		Expect(phases[1]).To(Equal([]Object{objects[0], objects[2]}))
+	})
+
+	It("tells which objects existed before they were applied", func() {
+		existing := []Object{
+			{"apiVersion": "v1", "kind": "Namespace", "metadata": map[string]interface{}{"name": "default"}},
+			{"apiVersion": "v1", "kind": "ConfigMap", "metadata": map[string]interface{}{"namespace": "default", "name": "my-config"}},
+			{"apiVersion": "apps/v1", "kind": "Deployment", "metadata": map[string]interface{}{"namespace": "my-ns", "name": "web"}},
+		}
+
+		Expect(containsObject(existing, Object{"apiVersion": "v1", "kind": "Namespace", "metadata": map[string]interface{}{"name": "default"}})).To(BeTrue())
+		Expect(containsObject(existing, Object{"apiVersion": "v1", "kind": "ConfigMap", "metadata": map[string]interface{}{"name": "my-config"}})).To(BeTrue())
+		Expect(containsObject(existing, Object{"apiVersion": "apps/v1beta2", "kind": "Deployment", "metadata": map[string]interface{}{"namespace": "my-ns", "name": "web"}})).To(BeTrue())
+
+		Expect(containsObject(existing, Object{"apiVersion": "v1", "kind": "ConfigMap", "metadata": map[string]interface{}{"namespace": "my-ns", "name": "my-config"}})).To(BeFalse())
+		Expect(containsObject(existing, Object{"apiVersion": "v1", "kind": "Secret", "metadata": map[string]interface{}{"name": "my-config"}})).To(BeFalse())
+		Expect(containsObject(existing, Object{"apiVersion": "extensions/v1beta1", "kind": "Deployment", "metadata": map[string]interface{}{"namespace": "my-ns", "name": "web"}})).To(BeFalse())
+	})
+
+	It("finds conditions", func() {
+		crd := Object{"status": map[string]interface{}{"conditions": []interface{}{
+			map[string]interface{}{"type": "NamesAccepted", "status": "True"},
+			map[string]interface{}{"type": "Established", "status": "False"},
+		}}}
+		Expect(hasCondition(crd, "NamesAccepted", "True")).To(BeTrue())
+		Expect(hasCondition(crd, "Established", "True")).To(BeFalse())
+		Expect(hasCondition(Object{}, "Established", "True")).To(BeFalse())
+	})
+
+	It("only reads manifest files", func() {
+		Expect(isManifestFile(filepath.Join("some", "dir", "deployment.yaml"))).To(BeTrue())
+		Expect(isManifestFile("crd.yml")).To(BeTrue())
+		Expect(isManifestFile("list.json")).To(BeTrue())
+		Expect(isManifestFile("README.md")).To(BeFalse())
+	})
+
+	Context("when a manifest file cannot be read", func() {
+		var dir string
+
+		BeforeEach(func() {
+			var err error
+			dir, err = ioutil.TempDir("", "k8s_test_framework_")
+			Expect(err).NotTo(HaveOccurred())
+		})
+		AfterEach(func() {
+			Expect(os.RemoveAll(dir)).To(Succeed())
+		})
+
+		It("returns an error before applying anything", func() {
+			Expect(ioutil.WriteFile(filepath.Join(dir, "broken.yaml"), []byte("kind: [\n"), 0600)).To(Succeed())
+
+			_, err := (&ControlPlane{}).ApplyManifests(dir, filepath.Join(dir, "missing"))
+			Expect(err).To(HaveOccurred())
+		})
+	})
+})
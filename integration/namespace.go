@@ -0,0 +1,164 @@
+package integration
+
+import (
+	"fmt"
+	"strings"
+)
+
+var namespacesResource = GroupVersionResource{Version: "v1", Resource: "namespaces"}
+
+// Namespace is a namespace of its own for a spec, on a ControlPlane shared
+// between specs. It is a lighter alternative to Reset, for specs which only
+// create namespaced objects:
+//
+//	var ns *integration.Namespace
+//	BeforeEach(func() {
+//		var err error
+//		ns, err = cp.CreateNamespace("widgets")
+//		Expect(err).NotTo(HaveOccurred())
+//	})
+//	AfterEach(func() { Expect(ns.Delete()).To(Succeed()) })
+//	It("creates a widget", func() {
+//		_, _, err := ns.KubeCtl().Run("create", "configmap", "my-widget")
+//		// ...
+//	})
+type Namespace struct {
+	// Name is the unique name of the namespace, e.g. "widgets-x7k2p".
+	Name string
+
+	controlPlane *ControlPlane
+	client       *RESTClient
+}
+
+// CreateNamespace creates a namespace with a unique name starting with the
+// prefix, e.g. the name of the spec's Describe. If the prefix is empty, the
+// name starts with "test-".
+func (f *ControlPlane) CreateNamespace(prefix string) (*Namespace, error) {
+	client, err := f.adminRESTClient()
+	if err != nil {
+		return nil, err
+	}
+	if prefix == "" {
+		prefix = "test"
+	}
+
+	created, err := client.Create(namespacesResource, "", Object{
+		"apiVersion": "v1",
+		"kind":       "Namespace",
+		"metadata":   map[string]interface{}{"generateName": strings.TrimSuffix(prefix, "-") + "-"},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &Namespace{Name: created.Name(), controlPlane: f, client: client}, nil
+}
+
+// KubeCtl returns a KubeCtl like ControlPlane.KubeCtl() does, which works in
+// this namespace unless told otherwise with "--namespace".
+func (n *Namespace) KubeCtl() *KubeCtl {
+	k := n.controlPlane.KubeCtl()
+	k.Opts = append(k.Opts, fmt.Sprintf("--namespace=%s", n.Name))
+	return k
+}
+
+// Delete deletes all objects in the namespace, and the namespace itself.
+//
+// As there is no namespace controller, which would do this otherwise, Delete
+// does it by itself: It deletes the objects without a grace period, and
+// removes their finalizers, so they are gone right away. Then it removes the
+// "kubernetes" finalizer from the namespace, which would otherwise stay
+// Terminating forever.
+func (n *Namespace) Delete() error {
+	if _, err := n.deleteContents(true); err != nil {
+		return err
+	}
+
+	err := n.client.Delete(namespacesResource, "", n.Name)
+	if IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return n.finalize()
+}
+
+// deleteContents deletes all objects in the namespace, without a grace
+// period. If force is set, their finalizers are removed first. It returns the
+// number of objects which had finalizers, and are therefore not gone yet,
+// unless force is set. The objects of unavailable group versions cannot be
+// deleted, so the groupDiscoveryFailedError reporting them is returned, after
+// the objects of all other resources have been deleted.
+func (n *Namespace) deleteContents(force bool) (int, error) {
+	resources, discoveryErr := n.client.discoverResources(true, "list", "delete")
+	if discoveryErr != nil && !isGroupDiscoveryFailed(discoveryErr) {
+		return 0, discoveryErr
+	}
+
+	remaining := 0
+	for _, gvr := range resources {
+		r, err := n.deleteAll(gvr, force)
+		if err != nil {
+			return 0, err
+		}
+		remaining += r
+	}
+	return remaining, discoveryErr
+}
+
+// deleteAll deletes all objects of the resource in the namespace, see
+// deleteContents.
+func (n *Namespace) deleteAll(gvr GroupVersionResource, force bool) (int, error) {
+	list, err := n.client.List(gvr, n.Name)
+	if IsNotFound(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	remaining := 0
+	for _, object := range list.Items() {
+		if finalizers, _ := object.Field("metadata.finalizers"); finalizers != nil {
+			if !force {
+				remaining++
+			} else {
+				_, err := n.client.Patch(gvr, n.Name, object.Name(), MergePatchType,
+					[]byte(`{"metadata": {"finalizers": null}}`))
+				if err != nil && !IsNotFound(err) {
+					return 0, err
+				}
+			}
+		}
+		if _, deleting := object.Field("metadata.deletionTimestamp"); deleting {
+			continue
+		}
+		err := n.client.Do("DELETE", n.client.ResourcePath(gvr, n.Name, object.Name())+"?gracePeriodSeconds=0", nil, nil)
+		if err != nil && !IsNotFound(err) {
+			return 0, err
+		}
+	}
+	return remaining, nil
+}
+
+// finalize finalizes the namespace, which is being deleted, by clearing its
+// spec.finalizers, and deletes it again, which now deletes it for good.
+func (n *Namespace) finalize() error {
+	namespace, err := n.client.Get(namespacesResource, "", n.Name)
+	if IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	namespace["spec"] = map[string]interface{}{"finalizers": []interface{}{}}
+	finalize := GroupVersionResource{Version: "v1", Resource: "namespaces/finalize"}
+	err = n.client.Do("PUT", n.client.ResourcePath(finalize, "", n.Name), namespace, nil)
+	if err == nil {
+		err = n.client.Delete(namespacesResource, "", n.Name)
+	}
+	if IsNotFound(err) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,98 @@
+package integration
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/ghttp"
+)
+
+var _ = Describe("Namespace", func() {
+	It("needs a started ControlPlane", func() {
+		_, err := (&ControlPlane{APIServer: &APIServer{}}).CreateNamespace("widgets")
+		Expect(err).To(MatchError(ContainSubstring("needs to be started")))
+	})
+
+	Describe("Delete", func() {
+		var (
+			server    *ghttp.Server
+			namespace *Namespace
+			requests  []string
+			finalized Object
+		)
+
+		BeforeEach(func() {
+			server = ghttp.NewServer()
+			serverURL, err := url.Parse(server.URL())
+			Expect(err).NotTo(HaveOccurred())
+			namespace = &Namespace{
+				Name:   "widgets-x7k2p",
+				client: &RESTClient{URL: *serverURL, httpClient: http.DefaultClient},
+			}
+			requests = []string{}
+			finalized = nil
+
+			record := func(rw http.ResponseWriter, req *http.Request) {
+				requests = append(requests, req.Method+" "+req.URL.RequestURI())
+				rw.Write([]byte(`{}`))
+			}
+			server.RouteToHandler("GET", "/apis", ghttp.RespondWith(http.StatusOK, `{"groups": []}`))
+			server.RouteToHandler("GET", "/api/v1", ghttp.RespondWith(http.StatusOK, `{
+				"groupVersion": "v1",
+				"resources": [
+					{"name": "configmaps", "namespaced": true, "verbs": ["create", "delete", "get", "list"]},
+					{"name": "namespaces", "namespaced": false, "verbs": ["create", "delete", "get", "list"]},
+					{"name": "pods/log", "namespaced": true, "verbs": ["get"]}
+				]
+			}`))
+			server.RouteToHandler("GET", "/api/v1/namespaces/widgets-x7k2p/configmaps", ghttp.RespondWith(http.StatusOK, `{"items": [
+				{"metadata": {"name": "plain"}},
+				{"metadata": {"name": "finalized", "finalizers": ["example.com/cleanup"]}}
+			]}`))
+			server.RouteToHandler("PATCH", "/api/v1/namespaces/widgets-x7k2p/configmaps/finalized", record)
+			server.RouteToHandler("DELETE", "/api/v1/namespaces/widgets-x7k2p/configmaps/plain", record)
+			server.RouteToHandler("DELETE", "/api/v1/namespaces/widgets-x7k2p/configmaps/finalized", record)
+			server.RouteToHandler("DELETE", "/api/v1/namespaces/widgets-x7k2p", record)
+			server.RouteToHandler("GET", "/api/v1/namespaces/widgets-x7k2p", ghttp.RespondWith(http.StatusOK, `{
+				"kind": "Namespace",
+				"metadata": {"name": "widgets-x7k2p"},
+				"spec": {"finalizers": ["kubernetes"]}
+			}`))
+			server.RouteToHandler("PUT", "/api/v1/namespaces/widgets-x7k2p/finalize", func(rw http.ResponseWriter, req *http.Request) {
+				body, err := ioutil.ReadAll(req.Body)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(json.Unmarshal(body, &finalized)).To(Succeed())
+				record(rw, req)
+			})
+		})
+		AfterEach(func() {
+			server.Close()
+		})
+
+		It("deletes the contents, and finalizes the namespace", func() {
+			Expect(namespace.Delete()).To(Succeed())
+
+			Expect(requests).To(Equal([]string{
+				"DELETE /api/v1/namespaces/widgets-x7k2p/configmaps/plain?gracePeriodSeconds=0",
+				"PATCH /api/v1/namespaces/widgets-x7k2p/configmaps/finalized",
+				"DELETE /api/v1/namespaces/widgets-x7k2p/configmaps/finalized?gracePeriodSeconds=0",
+				"DELETE /api/v1/namespaces/widgets-x7k2p",
+				"PUT /api/v1/namespaces/widgets-x7k2p/finalize",
+				"DELETE /api/v1/namespaces/widgets-x7k2p",
+			}))
+			Expect(finalized).To(HaveField("spec.finalizers", BeEmpty()))
+			Expect(finalized).To(HaveField("metadata.name", "widgets-x7k2p"))
+		})
+
+		It("is done if the namespace is gone already", func() {
+			server.RouteToHandler("DELETE", "/api/v1/namespaces/widgets-x7k2p", ghttp.RespondWith(http.StatusNotFound, `{"kind": "Status", "reason": "NotFound"}`))
+
+			Expect(namespace.Delete()).To(Succeed())
+			Expect(finalized).To(BeNil())
+		})
+	})
+})
@@ -0,0 +1,241 @@
+package integration
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/onsi/gomega"
+	"github.com/onsi/gomega/format"
+	"github.com/onsi/gomega/types"
+)
+
+// Object is a kubernetes object, or a list of them, decoded from JSON without
+// knowing its type. Numbers are decoded as float64.
+type Object map[string]interface{}
+
+// APIVersion returns the object's apiVersion, e.g. "apps/v1".
+func (o Object) APIVersion() string {
+	return o.stringField("apiVersion")
+}
+
+// Kind returns the object's kind, e.g. "Deployment".
+func (o Object) Kind() string {
+	return o.stringField("kind")
+}
+
+// Name returns the object's metadata.name.
+func (o Object) Name() string {
+	return o.stringField("metadata.name")
+}
+
+// Namespace returns the object's metadata.namespace, which is empty for
+// cluster scoped objects.
+func (o Object) Namespace() string {
+	return o.stringField("metadata.namespace")
+}
+
+// Items returns the items of a list.
+func (o Object) Items() []Object {
+	items, _ := o.Field("items")
+	list, _ := items.([]interface{})
+
+	objects := []Object{}
+	for _, item := range list {
+		if object, ok := item.(map[string]interface{}); ok {
+			objects = append(objects, Object(object))
+		}
+	}
+	return objects
+}
+
+// Field returns the value at the path, and whether it exists. The path
+// consists of field names separated by dots, and of indices or field names
+// in brackets, e.g. "spec.containers[0].image" or
+// "metadata.labels[app.kubernetes.io/name]". It can also be written like a
+// simple jsonpath, e.g. "{.spec.containers[0].image}".
+func (o Object) Field(path string) (interface{}, bool) {
+	segments, err := parseFieldPath(path)
+	if err != nil {
+		return nil, false
+	}
+
+	var current interface{} = map[string]interface{}(o)
+	for _, segment := range segments {
+		switch value := current.(type) {
+		case map[string]interface{}:
+			next, ok := value[segment]
+			if !ok {
+				return nil, false
+			}
+			current = next
+		case []interface{}:
+			i, err := strconv.Atoi(segment)
+			if err != nil || i < 0 || i >= len(value) {
+				return nil, false
+			}
+			current = value[i]
+		default:
+			return nil, false
+		}
+	}
+	return current, true
+}
+
+func (o Object) stringField(path string) string {
+	value, _ := o.Field(path)
+	s, _ := value.(string)
+	return s
+}
+
+// parseFieldPath splits a path like "a.b[0][c.d]" into "a", "b", "0", "c.d".
+func parseFieldPath(path string) ([]string, error) {
+	segments := []string{}
+	rest := path
+	if strings.HasPrefix(rest, "{") && strings.HasSuffix(rest, "}") {
+		rest = rest[1 : len(rest)-1]
+	}
+	for rest != "" {
+		switch rest[0] {
+		case '.':
+			rest = rest[1:]
+		case '[':
+			end := strings.Index(rest, "]")
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated bracket in field path %q", path)
+			}
+			segments = append(segments, rest[1:end])
+			rest = rest[end+1:]
+		default:
+			end := strings.IndexAny(rest, ".[")
+			if end < 0 {
+				end = len(rest)
+			}
+			segments = append(segments, rest[:end])
+			rest = rest[end:]
+		}
+	}
+	if len(segments) == 0 {
+		return nil, fmt.Errorf("empty field path")
+	}
+	return segments, nil
+}
+
+// GetJSON runs the command with "--output json" and decodes its output into
+// into, e.g.:
+//
+//	pod := struct {
+//		Status struct{ Phase string }
+//	}{}
+//	err := kubeCtl.GetJSON(&pod, "get", "pod", "my-pod")
+func (k *KubeCtl) GetJSON(into interface{}, args ...string) error {
+	out, err := k.runForOutput(append(args, "--output", "json")...)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal([]byte(out), into); err != nil {
+		return fmt.Errorf("could not decode the output of kubectl %s: %s", strings.Join(args, " "), err)
+	}
+	return nil
+}
+
+// GetObject runs the command with "--output json" and decodes its output
+// into an Object.
+func (k *KubeCtl) GetObject(args ...string) (Object, error) {
+	object := Object{}
+	if err := k.GetJSON(&object, args...); err != nil {
+		return nil, err
+	}
+	return object, nil
+}
+
+// JSONPath runs the command with "--output jsonpath=<template>" and returns
+// its output, e.g.:
+//
+//	uid, err := kubeCtl.JSONPath("{.metadata.uid}", "get", "pod", "my-pod")
+func (k *KubeCtl) JSONPath(template string, args ...string) (string, error) {
+	return k.runForOutput(append(args, "--output", "jsonpath="+template)...)
+}
+
+// HaveField succeeds if actual, an Object or an *ObjectRef, has a field at
+// the path (see Object.Field) which matches expected. Expected can either be
+// a matcher, or a value the field needs to be equal to. Numbers are compared
+// by their value, regardless of their type.
+//
+//	Expect(deployment).To(HaveField("spec.replicas", 3))
+//	Expect(pod).To(HaveField("spec.containers[0].image", HavePrefix("nginx")))
+func HaveField(path string, expected interface{}) types.GomegaMatcher {
+	return &haveFieldMatcher{path: path, expected: expected}
+}
+
+type haveFieldMatcher struct {
+	path     string
+	expected interface{}
+
+	found bool
+	value interface{}
+}
+
+func (m *haveFieldMatcher) Match(actual interface{}) (bool, error) {
+	object, err := toObject(actual)
+	if err != nil {
+		return false, fmt.Errorf("HaveField matcher %s", err)
+	}
+
+	m.value, m.found = object.Field(m.path)
+	if !m.found {
+		return false, nil
+	}
+	return m.valueMatcher().Match(m.value)
+}
+
+func (m *haveFieldMatcher) valueMatcher() types.GomegaMatcher {
+	if matcher, ok := m.expected.(types.GomegaMatcher); ok {
+		return matcher
+	}
+	switch reflect.ValueOf(m.expected).Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		if _, isNumber := m.value.(float64); isNumber {
+			return gomega.BeNumerically("==", m.expected)
+		}
+	}
+	return gomega.Equal(m.expected)
+}
+
+func (m *haveFieldMatcher) FailureMessage(actual interface{}) string {
+	if !m.found {
+		return format.Message(describeObject(actual), "to have a field at", m.path)
+	}
+	return fmt.Sprintf("Field %s: %s", m.path, m.valueMatcher().FailureMessage(m.value))
+}
+
+func (m *haveFieldMatcher) NegatedFailureMessage(actual interface{}) string {
+	return fmt.Sprintf("Field %s: %s", m.path, m.valueMatcher().NegatedFailureMessage(m.value))
+}
+
+// toObject converts the supported representations of an object to an Object.
+// ObjectRefs are fetched from the APIServer.
+func toObject(actual interface{}) (Object, error) {
+	switch object := actual.(type) {
+	case Object:
+		return object, nil
+	case *Object:
+		if object == nil {
+			return nil, fmt.Errorf("expects an Object. Got nil")
+		}
+		return *object, nil
+	case map[string]interface{}:
+		return Object(object), nil
+	case *ObjectRef:
+		fetched, err := object.Get()
+		if err != nil {
+			return nil, fmt.Errorf("could not get %s: %s", object, err)
+		}
+		return fetched, nil
+	}
+	return nil, fmt.Errorf("expects an Object or an *ObjectRef. Got:\n%s", format.Object(actual, 1))
+}
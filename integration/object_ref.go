@@ -0,0 +1,206 @@
+package integration
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/onsi/gomega/format"
+	"github.com/onsi/gomega/types"
+)
+
+// ObjectRef references an object of a ControlPlane by its resource,
+// namespace and name. The object is fetched, as the AdminUser, whenever a
+// matcher is applied to the ObjectRef, so it can be polled, e.g.:
+//
+//	web := cp.Ref(deployments, "default/web")
+//	Eventually(web).Should(integration.HaveCondition("Available", "True"))
+type ObjectRef struct {
+	GVR       GroupVersionResource
+	Namespace string
+	Name      string
+
+	controlPlane *ControlPlane
+}
+
+// Ref returns a reference to the object of the resource, given as
+// "namespace/name", or just "name" for cluster scoped resources.
+func (f *ControlPlane) Ref(gvr GroupVersionResource, ref string) *ObjectRef {
+	namespace, name := "", ref
+	if i := strings.Index(ref, "/"); i >= 0 {
+		namespace, name = ref[:i], ref[i+1:]
+	}
+	return &ObjectRef{GVR: gvr, Namespace: namespace, Name: name, controlPlane: f}
+}
+
+// Get fetches the object.
+func (r *ObjectRef) Get() (Object, error) {
+	client, err := r.controlPlane.adminRESTClient()
+	if err != nil {
+		return nil, err
+	}
+	return client.Get(r.GVR, r.Namespace, r.Name)
+}
+
+func (r *ObjectRef) String() string {
+	if r.Namespace == "" {
+		return fmt.Sprintf("%s %s", r.GVR, r.Name)
+	}
+	return fmt.Sprintf("%s %s/%s", r.GVR, r.Namespace, r.Name)
+}
+
+// adminRESTClient returns a RESTClient for the AdminUser, which is shared
+// by all ObjectRefs until the ControlPlane is started again.
+func (f *ControlPlane) adminRESTClient() (*RESTClient, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.adminClient == nil {
+		client, err := f.RESTClient()
+		if err != nil {
+			return nil, err
+		}
+		f.adminClient = client
+	}
+	return f.adminClient, nil
+}
+
+// describeObject keeps failure messages readable, as an ObjectRef would be
+// rendered with its whole ControlPlane otherwise.
+func describeObject(actual interface{}) interface{} {
+	if ref, ok := actual.(*ObjectRef); ok {
+		return ref.String()
+	}
+	return actual
+}
+
+// Exist succeeds if actual, an *ObjectRef, references an existing object.
+// Objects which are being deleted, but still have finalizers, exist.
+func Exist() types.GomegaMatcher {
+	return &existMatcher{}
+}
+
+type existMatcher struct{}
+
+func (m *existMatcher) Match(actual interface{}) (bool, error) {
+	return refExists("Exist", actual)
+}
+
+func (m *existMatcher) FailureMessage(actual interface{}) string {
+	return format.Message(describeObject(actual), "to exist")
+}
+
+func (m *existMatcher) NegatedFailureMessage(actual interface{}) string {
+	return format.Message(describeObject(actual), "not to exist")
+}
+
+// BeDeleted succeeds if actual, an *ObjectRef, references an object which
+// does not exist (anymore). Objects which are being deleted, but still have
+// finalizers, are not deleted yet.
+func BeDeleted() types.GomegaMatcher {
+	return &beDeletedMatcher{}
+}
+
+type beDeletedMatcher struct{}
+
+func (m *beDeletedMatcher) Match(actual interface{}) (bool, error) {
+	exists, err := refExists("BeDeleted", actual)
+	return !exists, err
+}
+
+func (m *beDeletedMatcher) FailureMessage(actual interface{}) string {
+	return format.Message(describeObject(actual), "to be deleted")
+}
+
+func (m *beDeletedMatcher) NegatedFailureMessage(actual interface{}) string {
+	return format.Message(describeObject(actual), "not to be deleted")
+}
+
+func refExists(matcherName string, actual interface{}) (bool, error) {
+	ref, ok := actual.(*ObjectRef)
+	if !ok {
+		return false, fmt.Errorf("%s matcher expects an *ObjectRef. Got:\n%s", matcherName, format.Object(actual, 1))
+	}
+
+	_, err := ref.Get()
+	if IsNotFound(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// HaveCondition succeeds if actual, an Object or an *ObjectRef, has a
+// condition of the type with the status, e.g. "True", in its
+// status.conditions.
+func HaveCondition(conditionType, status string) types.GomegaMatcher {
+	return &haveConditionMatcher{conditionType: conditionType, status: status}
+}
+
+type haveConditionMatcher struct {
+	conditionType string
+	status        string
+
+	conditions interface{}
+}
+
+func (m *haveConditionMatcher) Match(actual interface{}) (bool, error) {
+	object, err := toObject(actual)
+	if err != nil {
+		return false, fmt.Errorf("HaveCondition matcher %s", err)
+	}
+	m.conditions, _ = object.Field("status.conditions")
+	return hasCondition(object, m.conditionType, m.status), nil
+}
+
+func (m *haveConditionMatcher) FailureMessage(actual interface{}) string {
+	return format.Message(describeObject(actual),
+		fmt.Sprintf("to have condition %s=%s, its conditions are", m.conditionType, m.status), m.conditions)
+}
+
+func (m *haveConditionMatcher) NegatedFailureMessage(actual interface{}) string {
+	return format.Message(describeObject(actual),
+		fmt.Sprintf("not to have condition %s=%s, its conditions are", m.conditionType, m.status), m.conditions)
+}
+
+// HaveOwnerReference succeeds if actual, an Object or an *ObjectRef, has an
+// owner reference to the object of the kind with the name in its
+// metadata.ownerReferences, e.g. HaveOwnerReference("ReplicaSet", "web-1234").
+func HaveOwnerReference(kind, name string) types.GomegaMatcher {
+	return &haveOwnerReferenceMatcher{kind: kind, name: name}
+}
+
+type haveOwnerReferenceMatcher struct {
+	kind string
+	name string
+
+	ownerReferences interface{}
+}
+
+func (m *haveOwnerReferenceMatcher) Match(actual interface{}) (bool, error) {
+	object, err := toObject(actual)
+	if err != nil {
+		return false, fmt.Errorf("HaveOwnerReference matcher %s", err)
+	}
+
+	m.ownerReferences, _ = object.Field("metadata.ownerReferences")
+	references, _ := m.ownerReferences.([]interface{})
+	for _, r := range references {
+		reference, ok := r.(map[string]interface{})
+		if ok && reference["kind"] == m.kind && reference["name"] == m.name {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (m *haveOwnerReferenceMatcher) FailureMessage(actual interface{}) string {
+	return format.Message(describeObject(actual),
+		fmt.Sprintf("to be owned by %s %s, its owner references are", m.kind, m.name), m.ownerReferences)
+}
+
+func (m *haveOwnerReferenceMatcher) NegatedFailureMessage(actual interface{}) string {
+	return format.Message(describeObject(actual),
+		fmt.Sprintf("not to be owned by %s %s, its owner references are", m.kind, m.name), m.ownerReferences)
+}
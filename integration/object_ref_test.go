@@ -0,0 +1,120 @@
+package integration
+
+import (
+	"net/http"
+	"net/url"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/ghttp"
+)
+
+var _ = Describe("ObjectRef matchers", func() {
+	var (
+		server *ghttp.Server
+		cp     *ControlPlane
+	)
+
+	deployments := GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"}
+	path := "/apis/apps/v1/namespaces/default/deployments/web"
+
+	BeforeEach(func() {
+		server = ghttp.NewServer()
+		serverURL, err := url.Parse(server.URL())
+		Expect(err).NotTo(HaveOccurred())
+
+		// The admin client is faked, so the ControlPlane does not need to be
+		// started.
+		cp = &ControlPlane{
+			adminClient: &RESTClient{URL: *serverURL, httpClient: http.DefaultClient},
+		}
+	})
+	AfterEach(func() {
+		server.Close()
+	})
+
+	respondWithDeployment := func() http.HandlerFunc {
+		return ghttp.CombineHandlers(
+			ghttp.VerifyRequest("GET", path),
+			ghttp.RespondWith(http.StatusOK, `{
+				"kind": "Deployment",
+				"metadata": {
+					"name": "web",
+					"namespace": "default",
+					"ownerReferences": [{"kind": "App", "name": "shop"}]
+				},
+				"spec": {"replicas": 3},
+				"status": {"conditions": [{"type": "Available", "status": "True"}]}
+			}`),
+		)
+	}
+	respondWithNotFound := func() http.HandlerFunc {
+		return ghttp.RespondWith(http.StatusNotFound, `{"kind": "Status", "reason": "NotFound"}`)
+	}
+
+	It("parses references", func() {
+		ref := cp.Ref(deployments, "default/web")
+		Expect(ref.Namespace).To(Equal("default"))
+		Expect(ref.Name).To(Equal("web"))
+		Expect(ref.String()).To(Equal("apps/v1/deployments default/web"))
+
+		namespaces := GroupVersionResource{Version: "v1", Resource: "namespaces"}
+		ref = cp.Ref(namespaces, "kube-system")
+		Expect(ref.Namespace).To(BeEmpty())
+		Expect(ref.Name).To(Equal("kube-system"))
+	})
+
+	It("matches existing objects", func() {
+		server.AppendHandlers(respondWithDeployment(), respondWithDeployment(), respondWithDeployment(), respondWithDeployment(), respondWithDeployment())
+		web := cp.Ref(deployments, "default/web")
+
+		Expect(web).To(Exist())
+		Expect(web).NotTo(BeDeleted())
+		Expect(web).To(HaveField("{.spec.replicas}", 3))
+		Expect(web).To(HaveCondition("Available", "True"))
+		Expect(web).To(HaveOwnerReference("App", "shop"))
+	})
+
+	It("matches deleted objects", func() {
+		server.AppendHandlers(respondWithNotFound(), respondWithNotFound(), respondWithNotFound())
+		web := cp.Ref(deployments, "default/web")
+
+		Expect(web).NotTo(Exist())
+		Expect(web).To(BeDeleted())
+
+		_, err := HaveField("spec.replicas", 3).Match(web)
+		Expect(err).To(MatchError(ContainSubstring("could not get apps/v1/deployments default/web")))
+	})
+
+	It("surfaces other errors", func() {
+		server.AppendHandlers(ghttp.RespondWith(http.StatusInternalServerError, "broken"))
+
+		_, err := Exist().Match(cp.Ref(deployments, "default/web"))
+		Expect(err).To(MatchError(ContainSubstring("broken")))
+	})
+
+	It("matches Objects without fetching them", func() {
+		object := Object{
+			"metadata": map[string]interface{}{"ownerReferences": []interface{}{
+				map[string]interface{}{"kind": "ReplicaSet", "name": "web-1234"},
+			}},
+			"status": map[string]interface{}{"conditions": []interface{}{
+				map[string]interface{}{"type": "Ready", "status": "False"},
+			}},
+		}
+
+		Expect(object).To(HaveCondition("Ready", "False"))
+		Expect(object).NotTo(HaveCondition("Ready", "True"))
+		Expect(object).To(HaveOwnerReference("ReplicaSet", "web-1234"))
+		Expect(object).NotTo(HaveOwnerReference("ReplicaSet", "web-5678"))
+
+		_, err := Exist().Match(object)
+		Expect(err).To(MatchError(ContainSubstring("Exist matcher expects an *ObjectRef")))
+	})
+
+	It("describes references in failure messages", func() {
+		web := cp.Ref(deployments, "default/web")
+		Expect(HaveCondition("Available", "True").FailureMessage(web)).To(ContainSubstring("apps/v1/deployments default/web"))
+		Expect(BeDeleted().FailureMessage(web)).To(ContainSubstring("to be deleted"))
+	})
+})
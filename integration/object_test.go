@@ -0,0 +1,99 @@
+package integration_test
+
+import (
+	"encoding/json"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/kubernetes-sigs/testing_frameworks/integration"
+)
+
+var _ = Describe("Object", func() {
+	var object Object
+
+	BeforeEach(func() {
+		object = Object{}
+		Expect(json.Unmarshal([]byte(`{
+			"apiVersion": "apps/v1",
+			"kind": "Deployment",
+			"metadata": {
+				"name": "my-deployment",
+				"namespace": "my-ns",
+				"labels": {"app.kubernetes.io/name": "my-app"}
+			},
+			"spec": {
+				"replicas": 3,
+				"template": {"spec": {"containers": [{"image": "nginx:1.15"}]}}
+			}
+		}`), &object)).To(Succeed())
+	})
+
+	It("knows its identity", func() {
+		Expect(object.APIVersion()).To(Equal("apps/v1"))
+		Expect(object.Kind()).To(Equal("Deployment"))
+		Expect(object.Name()).To(Equal("my-deployment"))
+		Expect(object.Namespace()).To(Equal("my-ns"))
+	})
+
+	It("looks up fields by path", func() {
+		image, found := object.Field("spec.template.spec.containers[0].image")
+		Expect(found).To(BeTrue())
+		Expect(image).To(Equal("nginx:1.15"))
+		label, found := object.Field("metadata.labels[app.kubernetes.io/name]")
+		Expect(found).To(BeTrue())
+		Expect(label).To(Equal("my-app"))
+
+		_, found = object.Field("spec.template.spec.containers[1].image")
+		Expect(found).To(BeFalse())
+		_, found = object.Field("metadata.name.first")
+		Expect(found).To(BeFalse())
+		_, found = object.Field("metadata[name")
+		Expect(found).To(BeFalse())
+	})
+
+	It("returns the items of lists", func() {
+		list := Object{"items": []interface{}{map[string]interface{}(object)}}
+		Expect(list.Items()).To(HaveLen(1))
+		Expect(list.Items()[0].Name()).To(Equal("my-deployment"))
+	})
+
+	Describe("HaveField", func() {
+		It("matches values and matchers", func() {
+			Expect(object).To(HaveField("spec.replicas", 3))
+			Expect(object).To(HaveField("metadata.name", HavePrefix("my-")))
+			Expect(object).NotTo(HaveField("spec.replicas", 2))
+			Expect(object).NotTo(HaveField("spec.paused", true))
+		})
+
+		It("errors for anything but objects", func() {
+			_, err := HaveField("spec", 1).Match("not an object")
+			Expect(err).To(HaveOccurred())
+		})
+	})
+})
+
+var _ = Describe("KubeCtl output decoding", func() {
+	It("decodes the JSON output", func() {
+		k := &KubeCtl{Path: "bash"}
+		object, err := k.GetObject("-c", `echo "{\"args\": \"$0 $1\"}"`)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(object).To(HaveField("args", "--output json"))
+
+		into := struct{ Args string }{}
+		Expect(k.GetJSON(&into, "-c", `echo "{\"args\": \"$0 $1\"}"`)).To(Succeed())
+		Expect(into.Args).To(Equal("--output json"))
+	})
+
+	It("fails on output which is not JSON", func() {
+		k := &KubeCtl{Path: "bash"}
+		_, err := k.GetObject("-c", "echo 'not json'")
+		Expect(err).To(MatchError(ContainSubstring("could not decode")))
+	})
+
+	It("runs jsonpath queries", func() {
+		k := &KubeCtl{Path: "bash"}
+		Expect(k.JSONPath("{.metadata.uid}", "-c", `echo -n "$0 $1"`)).
+			To(Equal("--output jsonpath={.metadata.uid}"))
+	})
+})
@@ -0,0 +1,177 @@
+package integration
+
+import (
+	"crypto/rsa"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"path/filepath"
+	"time"
+
+	"github.com/kubernetes-sigs/testing_frameworks/integration/internal"
+)
+
+// OIDCIssuer is a minimal OpenID Connect issuer, served over TLS from within
+// the test process. It serves the discovery document and the signing keys,
+// which is all the APIServer needs to verify ID tokens, and it can mint ID
+// tokens with arbitrary claims.
+//
+// To use it, configure it on the APIServer before starting it:
+//
+//	issuer := &integration.OIDCIssuer{}
+//	cp := &integration.ControlPlane{
+//		APIServer: &integration.APIServer{OIDCIssuer: issuer},
+//	}
+//	cp.Start()
+//	token, err := issuer.IDToken(map[string]interface{}{
+//		"sub":    "jane",
+//		"groups": []string{"devs"},
+//	})
+//	kubeCtl := cp.KubeCtlWithToken(token)
+type OIDCIssuer struct {
+	// ClientID is the client ID the APIServer expects as the audience of ID
+	// tokens.
+	//
+	// If not specified, this defaults to "kubernetes".
+	ClientID string
+
+	// UsernameClaim is the claim the APIServer takes the username from.
+	//
+	// If not specified, this defaults to "sub".
+	UsernameClaim string
+
+	// GroupsClaim is the claim the APIServer takes the user's groups from.
+	//
+	// If not specified, this defaults to "groups".
+	GroupsClaim string
+
+	// UsernamePrefix is prepended to the usernames by the APIServer. The
+	// special value "-" disables prefixing.
+	//
+	// If not specified, this defaults to "-".
+	UsernamePrefix string
+
+	key     *rsa.PrivateKey
+	server  *internal.TLSServer
+	running bool
+	url     string
+	caFile  string
+}
+
+// URL returns the URL of the issuer, which is also the "iss" claim of the ID
+// tokens. It is empty if the issuer has not been started yet.
+func (i *OIDCIssuer) URL() string {
+	return i.url
+}
+
+// CAFile returns the path to the certificate of the CA which issued the
+// issuer's serving certificate. It is empty if the issuer has not been started
+// yet.
+func (i *OIDCIssuer) CAFile() string {
+	return i.caFile
+}
+
+// IDToken mints a signed ID token with the claims. The "iss", "aud", "iat"
+// and "exp" claims are added, unless already present.
+func (i *OIDCIssuer) IDToken(claims map[string]interface{}) (string, error) {
+	if !i.running {
+		return "", fmt.Errorf("the OIDCIssuer needs to be started before minting ID tokens")
+	}
+
+	now := time.Now()
+	defaults := map[string]interface{}{
+		"iss": i.URL(),
+		"aud": i.ClientID,
+		"iat": now.Unix(),
+		"exp": now.Add(time.Hour).Unix(),
+	}
+
+	token := map[string]interface{}{}
+	for claim, value := range defaults {
+		token[claim] = value
+	}
+	for claim, value := range claims {
+		token[claim] = value
+	}
+
+	return internal.SignJWT(i.key, token)
+}
+
+// start starts serving the issuer and writes its CA certificate into dir.
+// When started again, e.g. with a restarted APIServer, it is served on the
+// same URL, with the same CA and signing key, so tokens minted before stay
+// valid.
+func (i *OIDCIssuer) start(dir string) error {
+	if i.ClientID == "" {
+		i.ClientID = "kubernetes"
+	}
+	if i.UsernameClaim == "" {
+		i.UsernameClaim = "sub"
+	}
+	if i.GroupsClaim == "" {
+		i.GroupsClaim = "groups"
+	}
+	if i.UsernamePrefix == "" {
+		i.UsernamePrefix = "-"
+	}
+
+	if i.key == nil {
+		var err error
+		i.key, err = internal.NewRSAKey()
+		if err != nil {
+			return err
+		}
+	}
+
+	if i.server == nil {
+		mux := http.NewServeMux()
+		mux.HandleFunc("/.well-known/openid-configuration", i.serveDiscovery)
+		mux.HandleFunc("/keys", i.serveKeys)
+
+		var err error
+		i.server, err = internal.StartTLSServer("oidc-issuer", mux)
+		if err != nil {
+			return err
+		}
+		i.url = i.server.URL.String()
+	} else if !i.running {
+		if err := i.server.Restart(); err != nil {
+			return err
+		}
+	}
+	i.running = true
+
+	i.caFile = filepath.Join(dir, "oidc-ca.crt")
+	return ioutil.WriteFile(i.caFile, i.server.CA.CertBytes(), 0600)
+}
+
+// stop stops serving the issuer.
+func (i *OIDCIssuer) stop() error {
+	if !i.running {
+		return nil
+	}
+	i.running = false
+	return i.server.Close()
+}
+
+func (i *OIDCIssuer) serveDiscovery(rw http.ResponseWriter, req *http.Request) {
+	writeJSON(rw, map[string]interface{}{
+		"issuer":                                i.URL(),
+		"jwks_uri":                              i.URL() + "/keys",
+		"authorization_endpoint":                i.URL() + "/auth",
+		"response_types_supported":              []string{"id_token"},
+		"subject_types_supported":               []string{"public"},
+		"id_token_signing_alg_values_supported": []string{"RS256"},
+	})
+}
+
+func (i *OIDCIssuer) serveKeys(rw http.ResponseWriter, req *http.Request) {
+	jwk, err := internal.NewJSONWebKey(i.key)
+	if err != nil {
+		http.Error(rw, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(rw, map[string]interface{}{
+		"keys": []internal.JSONWebKey{jwk},
+	})
+}
@@ -0,0 +1,109 @@
+package integration
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("OIDCIssuer", func() {
+	var (
+		issuer *OIDCIssuer
+		dir    string
+		client *http.Client
+	)
+
+	BeforeEach(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "k8s_test_framework_")
+		Expect(err).NotTo(HaveOccurred())
+
+		issuer = &OIDCIssuer{}
+		Expect(issuer.start(dir)).To(Succeed())
+
+		caData, err := ioutil.ReadFile(issuer.CAFile())
+		Expect(err).NotTo(HaveOccurred())
+		pool := x509.NewCertPool()
+		Expect(pool.AppendCertsFromPEM(caData)).To(BeTrue())
+		client = &http.Client{Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{RootCAs: pool},
+		}}
+	})
+	AfterEach(func() {
+		Expect(issuer.stop()).To(Succeed())
+		Expect(os.RemoveAll(dir)).To(Succeed())
+	})
+
+	getJSON := func(url string) map[string]interface{} {
+		res, err := client.Get(url)
+		ExpectWithOffset(1, err).NotTo(HaveOccurred())
+		defer res.Body.Close()
+		ExpectWithOffset(1, res.StatusCode).To(Equal(http.StatusOK))
+		body := map[string]interface{}{}
+		ExpectWithOffset(1, json.NewDecoder(res.Body).Decode(&body)).To(Succeed())
+		return body
+	}
+
+	It("defaults its configuration", func() {
+		Expect(issuer.ClientID).To(Equal("kubernetes"))
+		Expect(issuer.UsernameClaim).To(Equal("sub"))
+		Expect(issuer.GroupsClaim).To(Equal("groups"))
+		Expect(issuer.UsernamePrefix).To(Equal("-"))
+	})
+
+	It("serves the discovery document and the signing keys", func() {
+		discovery := getJSON(issuer.URL() + "/.well-known/openid-configuration")
+		Expect(discovery).To(HaveKeyWithValue("issuer", issuer.URL()))
+
+		keys := getJSON(discovery["jwks_uri"].(string))
+		Expect(keys["keys"]).To(HaveLen(1))
+	})
+
+	It("mints ID tokens with the default and the given claims", func() {
+		token, err := issuer.IDToken(map[string]interface{}{
+			"sub":    "jane",
+			"groups": []string{"devs"},
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		parts := strings.Split(token, ".")
+		Expect(parts).To(HaveLen(3))
+		payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+		Expect(err).NotTo(HaveOccurred())
+		claims := map[string]interface{}{}
+		Expect(json.Unmarshal(payload, &claims)).To(Succeed())
+
+		Expect(claims).To(HaveKeyWithValue("iss", issuer.URL()))
+		Expect(claims).To(HaveKeyWithValue("aud", "kubernetes"))
+		Expect(claims).To(HaveKeyWithValue("sub", "jane"))
+		Expect(claims).To(HaveKey("exp"))
+	})
+
+	It("keeps its URL, CA and signing key when started again", func() {
+		url := issuer.URL()
+		caData, err := ioutil.ReadFile(issuer.CAFile())
+		Expect(err).NotTo(HaveOccurred())
+		keys := getJSON(url + "/keys")
+
+		Expect(issuer.stop()).To(Succeed())
+		Expect(issuer.start(dir)).To(Succeed())
+
+		Expect(issuer.URL()).To(Equal(url))
+		Expect(ioutil.ReadFile(issuer.CAFile())).To(Equal(caData))
+		Expect(getJSON(url + "/keys")).To(Equal(keys))
+	})
+
+	It("cannot mint tokens when stopped", func() {
+		Expect(issuer.stop()).To(Succeed())
+		_, err := issuer.IDToken(nil)
+		Expect(err).To(MatchError(ContainSubstring("needs to be started")))
+	})
+})
@@ -0,0 +1,199 @@
+package integration
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"time"
+
+	"github.com/kubernetes-sigs/testing_frameworks/integration/internal"
+)
+
+// Process knows how to run a binary of your own, e.g. a controller or an
+// extension apiserver under test, the same way the other components are run.
+//
+// Configure it on a ControlPlane, or pass it to ControlPlane.StartProcess,
+// to have it started with a kubeconfig to connect to the APIServer:
+//
+//	controller := &integration.Process{
+//		Name: "my-controller",
+//		Args: []string{"--kubeconfig={{ .KubeConfigFile }}", "--metrics-addr={{ .URL.Host }}"},
+//		HealthCheckEndpoint: "/metrics",
+//	}
+//	cp := &integration.ControlPlane{Processes: []*integration.Process{controller}}
+type Process struct {
+	// Name is the name of the binary, e.g. "my-controller", which is used to
+	// find it if Path is not specified.
+	Name string
+
+	// Path is the path to the binary.
+	//
+	// If this is left as the empty string, we will attempt to locate a binary
+	// named Name, by checking for an environment variable derived from it,
+	// e.g. TEST_ASSET_MY_CONTROLLER, and the default test assets directory.
+	// See the "Binaries" section above (in doc.go) for details.
+	Path string
+
+	// Args is a list of arguments which will passed to the binary. Before they
+	// are passed on, they will be evaluated as go-template strings. This means
+	// you can use fields which are defined and exported on this Process struct
+	// (e.g. "--kubeconfig={{ .KubeConfigFile }}" or "--port={{ .URL.Port }}").
+	// Those templates will be evaluated after the defaulting of the Process'
+	// fields has already happened and just before the binary actually gets
+	// started.
+	Args []string
+
+	// Env is a list of environment variables, e.g. "LOG_LEVEL=debug", the
+	// binary is started with in addition to those of the test process. They
+	// are evaluated as go-templates, like the Args. If KubeConfigFile is set,
+	// KUBECONFIG points to it, unless Env sets it, too.
+	Env []string
+
+	// URL is the address the Process serves its health checks on, if any,
+	// e.g. to be passed on with "--listen={{ .URL.Host }}".
+	//
+	// If this is not specified, we default to a random free port on localhost.
+	URL *url.URL
+
+	// HealthCheckEndpoint is the path on the URL, e.g. "/healthz", which
+	// responds with 200 OK once the Process is ready, polled with
+	// HealthCheckClient or, if not specified, the default http.Client. If
+	// this is not specified, the Process is ready once it writes the
+	// StartMessage to its stderr. If that is not specified either, the
+	// Process is ready as soon as it has been started.
+	HealthCheckEndpoint string
+	HealthCheckClient   *http.Client
+	StartMessage        string
+
+	// Dir is a directory for the Process' files, e.g. its certificates.
+	//
+	// If left unspecified, then the Start() method will create a fresh temporary
+	// directory, and the Stop() method will clean it up.
+	Dir string
+
+	// KubeConfigFile is the path to a kubeconfig the Process can use to
+	// connect to the APIServer.
+	//
+	// If this is not specified, a ControlPlane generates one, authenticating
+	// as the User.
+	KubeConfigFile string
+
+	// User is the user the kubeconfig generated by a ControlPlane
+	// authenticates as, e.g. to test the RBAC rules of a controller.
+	//
+	// If not specified, this defaults to a member of "system:masters" named
+	// after the Process.
+	User *User
+
+	// StartTimeout, StopTimeout specify the time the Process is allowed to
+	// take when starting and stopping before an error is emitted.
+	//
+	// If not specified, these default to 20 seconds.
+	StartTimeout time.Duration
+	StopTimeout  time.Duration
+
+	// Out, Err specify where the Process should write its StdOut, StdErr to.
+	//
+	// If not specified, the output will be discarded.
+	Out io.Writer
+	Err io.Writer
+
+	processState        *internal.ProcessState
+	generatedKubeConfig bool
+	// templateData is what the Args and Env are evaluated against, if not the
+	// Process itself, e.g. a component run as a Process.
+	templateData interface{}
+}
+
+// Start starts the Process, waits for it to come up, and returns an error,
+// if occurred.
+func (p *Process) Start() error {
+	var err error
+	if p.URL == nil {
+		p.URL, err = internal.NewLocalURL("http")
+		if err != nil {
+			return err
+		}
+	}
+
+	p.processState = &internal.ProcessState{}
+
+	p.processState.DefaultedProcessInput, err = internal.DoDefaulting(
+		p.Name,
+		p.URL,
+		p.Dir,
+		p.Path,
+		p.StartTimeout,
+		p.StopTimeout,
+	)
+	if err != nil {
+		return err
+	}
+
+	p.Dir = p.processState.Dir
+	p.Path = p.processState.Path
+	p.StartTimeout = p.processState.StartTimeout
+	p.StopTimeout = p.processState.StopTimeout
+
+	p.processState.HealthCheckEndpoint = p.HealthCheckEndpoint
+	p.processState.HealthCheckClient = p.HealthCheckClient
+	p.processState.StartMessage = p.StartMessage
+
+	var data interface{} = p
+	if p.templateData != nil {
+		data = p.templateData
+	}
+	p.processState.Args, err = internal.RenderTemplates(p.Args, data)
+	if err != nil {
+		return err
+	}
+	env, err := internal.RenderTemplates(p.Env, data)
+	if err != nil {
+		return err
+	}
+	p.processState.Env = []string{}
+	if p.KubeConfigFile != "" {
+		p.processState.Env = append(p.processState.Env, "KUBECONFIG="+p.KubeConfigFile)
+	}
+	p.processState.Env = append(p.processState.Env, env...)
+
+	return p.processState.Start(p.Out, p.Err)
+}
+
+// Stop stops this process gracefully, waits for its termination, and cleans up
+// the Dir if necessary.
+func (p *Process) Stop() error {
+	if p.processState == nil {
+		return nil
+	}
+	return p.processState.Stop()
+}
+
+// restart stops the Process, calls whileStopped, and starts it again, e.g.
+// to have it start over after the APIServer's state changed under its
+// caches.
+func (p *Process) restart(whileStopped func() error) error {
+	if p.processState == nil {
+		return whileStopped()
+	}
+	return p.processState.Restart(p.Out, p.Err, whileStopped)
+}
+
+// displayName returns the Name, or the name of the binary at Path.
+func (p *Process) displayName() string {
+	if p.Name != "" {
+		return p.Name
+	}
+	return filepath.Base(p.Path)
+}
+
+// user returns the User, or a member of "system:masters" named after the
+// Process.
+func (p *Process) user() User {
+	if p.User != nil {
+		return *p.User
+	}
+	return User{Name: fmt.Sprintf("system:integration:%s", p.displayName()), Groups: []string{"system:masters"}}
+}
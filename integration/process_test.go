@@ -0,0 +1,74 @@
+package integration
+
+import (
+	"regexp"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
+	"github.com/onsi/gomega/gexec"
+)
+
+var _ = Describe("Process", func() {
+	It("needs a name or a path", func() {
+		Expect((&Process{}).Start()).To(MatchError(ContainSubstring("name or path")))
+	})
+
+	It("can be stopped without having been started", func() {
+		Expect((&Process{}).Stop()).To(Succeed())
+	})
+
+	It("renders its arguments and environment, and points KUBECONFIG to its kubeconfig", func() {
+		stdout := gbytes.NewBuffer()
+		p := &Process{
+			Path: "bash",
+			Args: []string{"-c", `echo "$1 $KUBECONFIG $GREETING"; echo 'ready' >&2; sleep 10`, "--", "--port={{ .URL.Port }}"},
+			Env:  []string{"GREETING=hello from {{ .Dir }}"},
+
+			KubeConfigFile: "/some/kubeconfig",
+			StartMessage:   "ready",
+			Out:            stdout,
+		}
+		Expect(p.Start()).To(Succeed())
+		dir := p.Dir
+		Expect(dir).To(BeADirectory())
+
+		Eventually(stdout).Should(gbytes.Say("%s", regexp.QuoteMeta("--port="+p.URL.Port()+" /some/kubeconfig hello from "+dir+"\n")))
+
+		Expect(p.Stop()).To(Succeed())
+		Expect(p.processState.Session).To(gexec.Exit())
+		Expect(dir).NotTo(BeADirectory())
+	})
+
+	It("keeps a KUBECONFIG given in its environment", func() {
+		stdout := gbytes.NewBuffer()
+		p := &Process{
+			Path:           "bash",
+			Args:           []string{"-c", `echo "$KUBECONFIG"; echo 'ready' >&2; sleep 10`},
+			Env:            []string{"KUBECONFIG=/other/kubeconfig"},
+			KubeConfigFile: "/some/kubeconfig",
+			StartMessage:   "ready",
+			Out:            stdout,
+		}
+		Expect(p.Start()).To(Succeed())
+		defer p.Stop()
+
+		Eventually(stdout).Should(gbytes.Say("^/other/kubeconfig\n"))
+	})
+
+	It("authenticates as a member of system:masters named after it, unless told otherwise", func() {
+		Expect((&Process{Name: "my-controller"}).user()).To(Equal(User{
+			Name:   "system:integration:my-controller",
+			Groups: []string{"system:masters"},
+		}))
+		Expect((&Process{Path: "/usr/local/bin/my-apiserver"}).user().Name).To(Equal("system:integration:my-apiserver"))
+
+		jane := User{Name: "jane"}
+		Expect((&Process{Name: "my-controller", User: &jane}).user()).To(Equal(jane))
+	})
+
+	It("can only be started on a started ControlPlane", func() {
+		err := (&ControlPlane{}).StartProcess(&Process{Name: "my-controller"})
+		Expect(err).To(MatchError(ContainSubstring("needs to be started")))
+	})
+})
@@ -0,0 +1,164 @@
+package integration
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"strings"
+)
+
+// Subject is someone a role can be granted to, either a user or a group.
+type Subject struct {
+	// Kind is either "User" or "Group".
+	Kind string
+	Name string
+}
+
+// UserSubject returns the Subject for the user with the given name.
+func UserSubject(name string) Subject {
+	return Subject{Kind: "User", Name: name}
+}
+
+// GroupSubject returns the Subject for the group with the given name.
+func GroupSubject(name string) Subject {
+	return Subject{Kind: "Group", Name: name}
+}
+
+// Subject returns the Subject for this user.
+func (u User) Subject() Subject {
+	return UserSubject(u.Name)
+}
+
+// PolicyRule describes which verbs are allowed on which resources, e.g.
+// Verbs: []string{"get", "list"}, Resources: []string{"deployments.apps"}.
+type PolicyRule struct {
+	Verbs     []string
+	Resources []string
+}
+
+// CreateClusterRole creates a ClusterRole with the given name and rules.
+func (f *ControlPlane) CreateClusterRole(name string, rules ...PolicyRule) error {
+	return f.createRole([]string{"clusterrole", name}, rules)
+}
+
+// CreateRole creates a Role with the given name and rules in the namespace.
+func (f *ControlPlane) CreateRole(namespace, name string, rules ...PolicyRule) error {
+	return f.createRole([]string{"role", name, "--namespace", namespace}, rules)
+}
+
+func (f *ControlPlane) createRole(args []string, rules []PolicyRule) error {
+	if len(rules) == 0 {
+		return fmt.Errorf("expected at least one rule")
+	}
+
+	args = append([]string{"create"}, args...)
+	for _, rule := range rules {
+		args = append(args,
+			"--verb", strings.Join(rule.Verbs, ","),
+			"--resource", strings.Join(rule.Resources, ","),
+		)
+	}
+
+	_, err := f.KubeCtl().runForOutput(args...)
+	return err
+}
+
+// GrantClusterRole binds the ClusterRole to the subjects cluster wide, by
+// creating a ClusterRoleBinding.
+func (f *ControlPlane) GrantClusterRole(clusterRole string, subjects ...Subject) error {
+	return f.grant("clusterrolebinding", "", "--clusterrole", clusterRole, subjects)
+}
+
+// GrantClusterRoleInNamespace binds the ClusterRole to the subjects in the
+// namespace only, by creating a RoleBinding.
+func (f *ControlPlane) GrantClusterRoleInNamespace(namespace, clusterRole string, subjects ...Subject) error {
+	return f.grant("rolebinding", namespace, "--clusterrole", clusterRole, subjects)
+}
+
+// GrantRole binds the Role to the subjects in the namespace, by creating a
+// RoleBinding.
+func (f *ControlPlane) GrantRole(namespace, role string, subjects ...Subject) error {
+	return f.grant("rolebinding", namespace, "--role", role, subjects)
+}
+
+func (f *ControlPlane) grant(kind, namespace, roleFlag, role string, subjects []Subject) error {
+	if len(subjects) == 0 {
+		return fmt.Errorf("expected at least one subject to grant %s to", role)
+	}
+
+	name, err := bindingName(role)
+	if err != nil {
+		return err
+	}
+
+	args := []string{"create", kind, name, roleFlag, role}
+	if namespace != "" {
+		args = append(args, "--namespace", namespace)
+	}
+	for _, subject := range subjects {
+		switch subject.Kind {
+		case "User":
+			args = append(args, "--user", subject.Name)
+		case "Group":
+			args = append(args, "--group", subject.Name)
+		default:
+			return fmt.Errorf("unknown kind %q for subject %q", subject.Kind, subject.Name)
+		}
+	}
+
+	_, err = f.KubeCtl().runForOutput(args...)
+	return err
+}
+
+// bindingName returns a unique name for a binding of the role.
+func bindingName(role string) (string, error) {
+	suffix := make([]byte, 4)
+	if _, err := rand.Read(suffix); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s-%s", role, hex.EncodeToString(suffix)), nil
+}
+
+// CanI checks, by running `kubectl auth can-i` as the given user, if the user
+// is allowed to perform the verb on the resource. If namespace is empty, the
+// check is done cluster wide.
+//
+// It can be used like:
+//
+//	Expect(cp.CanI(user, "create", "deployments.apps", "my-ns")).To(BeTrue())
+func (f *ControlPlane) CanI(user User, verb, resource, namespace string) (bool, error) {
+	k, err := f.KubeCtlAs(user)
+	if err != nil {
+		return false, err
+	}
+
+	args := []string{"auth", "can-i", verb, resource}
+	if namespace == "" {
+		args = append(args, "--all-namespaces")
+	} else {
+		args = append(args, "--namespace", namespace)
+	}
+
+	// kubectl exits non-zero when the answer is "no", so we look at the output
+	// before looking at the error. The answer might be followed by a reason,
+	// e.g. "no - no RBAC policy matched".
+	stdout, _, runErr := k.Run(args...)
+	out, err := ioutil.ReadAll(stdout)
+	if err != nil {
+		return false, err
+	}
+	if answer := strings.Fields(string(out)); len(answer) > 0 {
+		switch answer[0] {
+		case "yes":
+			return true, nil
+		case "no":
+			return false, nil
+		}
+	}
+
+	if runErr != nil {
+		return false, runErr
+	}
+	return false, fmt.Errorf("unexpected output of kubectl %s: %s", strings.Join(args, " "), out)
+}
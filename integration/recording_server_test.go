@@ -0,0 +1,91 @@
+package integration
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/ghttp"
+)
+
+// recordingServer stands in for an APIServer in unit tests. It records the
+// requests sent to the handlers returned by record, and client talks to it.
+type recordingServer struct {
+	*ghttp.Server
+	client *RESTClient
+
+	mu       sync.Mutex
+	requests []recordedRequest
+}
+
+type recordedRequest struct {
+	// request is the method and the URI, e.g. "DELETE /api/v1/namespaces/default".
+	request string
+	body    []byte
+}
+
+func newRecordingServer() *recordingServer {
+	server := ghttp.NewServer()
+	serverURL, err := url.Parse(server.URL())
+	Expect(err).NotTo(HaveOccurred())
+	return &recordingServer{
+		Server: server,
+		client: &RESTClient{URL: *serverURL, httpClient: http.DefaultClient},
+	}
+}
+
+// record returns a handler, which records the request, and responds with the
+// code and the response.
+func (s *recordingServer) record(code int, response string) http.HandlerFunc {
+	return func(rw http.ResponseWriter, req *http.Request) {
+		body, err := ioutil.ReadAll(req.Body)
+		Expect(err).NotTo(HaveOccurred())
+		s.mu.Lock()
+		s.requests = append(s.requests, recordedRequest{request: req.Method + " " + req.URL.RequestURI(), body: body})
+		s.mu.Unlock()
+		rw.WriteHeader(code)
+		rw.Write([]byte(response))
+	}
+}
+
+// recorded returns the recorded requests, in order.
+func (s *recordingServer) recorded() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	requests := []string{}
+	for _, r := range s.requests {
+		requests = append(requests, r.request)
+	}
+	return requests
+}
+
+// recordedWithBodies returns the recorded requests, in order, each followed
+// by its body, if any.
+func (s *recordingServer) recordedWithBodies() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	requests := []string{}
+	for _, r := range s.requests {
+		requests = append(requests, strings.TrimSpace(r.request+" "+string(r.body)))
+	}
+	return requests
+}
+
+// recordedBody returns the decoded body of the last recorded request, e.g.
+// "POST /api/v1/nodes".
+func (s *recordingServer) recordedBody(request string) Object {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := len(s.requests) - 1; i >= 0; i-- {
+		if s.requests[i].request == request && len(s.requests[i].body) > 0 {
+			body := Object{}
+			Expect(json.Unmarshal(s.requests[i].body, &body)).To(Succeed())
+			return body
+		}
+	}
+	return nil
+}
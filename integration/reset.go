@@ -0,0 +1,361 @@
+package integration
+
+import (
+	"fmt"
+	"net/http"
+	"path"
+	"strings"
+	"sync"
+	"time"
+)
+
+// etcdRegistryPrefix is the prefix the APIServer stores all objects under.
+const etcdRegistryPrefix = "/registry/"
+
+// resetTimeout is the time the APIServer's watch caches are given to catch
+// up with a Reset.
+var resetTimeout = 10 * time.Second
+
+// bootstrapKeys are the keys of objects the APIServer creates for itself,
+// some of them only after it reports to be healthy. They are kept by Reset,
+// even if they did not exist when the ControlPlane was started.
+var bootstrapKeys = []string{
+	"/registry/namespaces/default",
+	"/registry/namespaces/kube-system",
+	"/registry/namespaces/kube-public",
+	"/registry/services/specs/default/kubernetes",
+	"/registry/services/endpoints/default/kubernetes",
+	"/registry/clusterroles/admin",
+	"/registry/clusterroles/edit",
+	"/registry/clusterroles/view",
+	"/registry/clusterroles/cluster-admin",
+	"/registry/clusterrolebindings/cluster-admin",
+}
+
+// bootstrapKeyPrefixes are the prefixes of the keys of such objects which
+// are kept by Reset as a whole: directories, ending in "/", and the names
+// reserved for the system, e.g. "system:".
+var bootstrapKeyPrefixes = []string{
+	"/registry/masterleases/",
+	"/registry/clusterroles/system:",
+	"/registry/clusterrolebindings/system:",
+	"/registry/roles/kube-system/",
+	"/registry/roles/kube-public/",
+	"/registry/rolebindings/kube-system/",
+	"/registry/rolebindings/kube-public/",
+	"/registry/priorityclasses/system-",
+}
+
+// Reset deletes all objects created since the ControlPlane was started, by
+// deleting their keys from Etcd, and waits for the APIServer to notice. This
+// gives each spec a clean cluster without restarting the ControlPlane:
+//
+//	BeforeSuite(func() { Expect(cp.Start()).To(Succeed()) })
+//	AfterSuite(func() { Expect(cp.Stop()).To(Succeed()) })
+//	AfterEach(func() { Expect(cp.Reset()).To(Succeed()) })
+//
+// Objects which existed when the ControlPlane was started, and the objects
+// the APIServer bootstraps itself with (e.g. the "default" namespace, the
+// "kubernetes" service and the "system:" roles), are kept, even if they have
+// been changed since. The APIServices of AggregatedAPIServers, and the
+// Services they refer to, are kept, too. Group versions which are unavailable,
+// e.g. as their AggregatedAPIServer has been stopped, are not waited for, as
+// their objects are not stored in the ControlPlane's Etcd.
+//
+// Objects are deleted without any finalizers or admission webhooks running.
+// Watchers started by Watch are stopped, like on Rollback. FakeNodes are
+// stopped, as their Nodes are deleted, too. Controllers are stopped while
+// objects are deleted, and started again afterwards.
+func (f *ControlPlane) Reset() error {
+	if f.Etcd == nil || f.Etcd.client == nil {
+		return fmt.Errorf("the ControlPlane needs to be started before it can be reset")
+	}
+	f.stopWatchers()
+	f.stopFakeNodes()
+	if f.Controllers != nil {
+		// Otherwise they would recreate objects while they are deleted.
+		f.Controllers.stopAndWait()
+		defer f.Controllers.start()
+	}
+
+	keys, err := f.Etcd.client.Keys(etcdRegistryPrefix)
+	if err != nil {
+		return err
+	}
+
+	deleted := false
+	for _, key := range keys {
+		if f.keepOnReset(key) {
+			continue
+		}
+		if _, err := f.Etcd.client.Delete(key); err != nil {
+			return err
+		}
+		deleted = true
+	}
+
+	if !deleted {
+		return nil
+	}
+	return f.waitForWatchCaches()
+}
+
+// recordResetBaseline records the keys which exist right after the
+// ControlPlane started, to be kept by Reset.
+func (f *ControlPlane) recordResetBaseline() error {
+	keys, err := f.Etcd.client.Keys(etcdRegistryPrefix)
+	if err != nil {
+		return err
+	}
+	f.resetBaseline = map[string]bool{}
+	for _, key := range keys {
+		f.resetBaseline[key] = true
+	}
+	return nil
+}
+
+func (f *ControlPlane) keepOnReset(key string) bool {
+	if f.resetBaseline[key] || containsString(bootstrapKeys, key) {
+		return true
+	}
+	for _, prefix := range bootstrapKeyPrefixes {
+		if strings.HasPrefix(key, prefix) {
+			return true
+		}
+	}
+	for _, server := range f.aggregatedAPIServers {
+		if containsString(server.keys(), key) {
+			return true
+		}
+	}
+	return false
+}
+
+// waitForWatchCaches waits until the APIServer's watch caches, which are fed
+// asynchronously from Etcd, serve the same objects as Etcd does, for every
+// resource which can be listed. Otherwise, clients listing or watching from
+// the caches, like informers, might still see the deleted objects.
+func (f *ControlPlane) waitForWatchCaches() error {
+	client, err := f.adminRESTClient()
+	if err != nil {
+		return err
+	}
+	resources, err := client.listableResources()
+	if err != nil && !isGroupDiscoveryFailed(err) {
+		return err
+	}
+
+	timedOut := time.After(resetTimeout)
+	for {
+		stale, err := client.staleCaches(resources)
+		if err != nil {
+			return err
+		}
+		if len(stale) == 0 {
+			return nil
+		}
+
+		select {
+		case <-timedOut:
+			return fmt.Errorf("timeout waiting for the watch caches of %v to catch up with the reset", stale)
+		case <-time.After(50 * time.Millisecond):
+		}
+	}
+}
+
+type apiResourceList struct {
+	GroupVersion string `json:"groupVersion"`
+	Resources    []struct {
+		Name       string   `json:"name"`
+		Kind       string   `json:"kind"`
+		Namespaced bool     `json:"namespaced"`
+		Verbs      []string `json:"verbs"`
+	} `json:"resources"`
+}
+
+type apiGroupList struct {
+	Groups []struct {
+		PreferredVersion struct {
+			GroupVersion string `json:"groupVersion"`
+		} `json:"preferredVersion"`
+	} `json:"groups"`
+}
+
+// apiResource is a resource found by discovery.
+type apiResource struct {
+	GVR        GroupVersionResource
+	Kind       string
+	Namespaced bool
+}
+
+// groupDiscoveryFailedError is returned by discoverAPIResources, along with the
+// resources of all other group versions, if some group versions are
+// unavailable, e.g. because the AggregatedAPIServer serving them has been
+// stopped.
+type groupDiscoveryFailedError struct {
+	groupVersions []string
+}
+
+func (e *groupDiscoveryFailedError) Error() string {
+	return fmt.Sprintf("unable to discover the resources of the unavailable group versions %s",
+		strings.Join(e.groupVersions, ", "))
+}
+
+func isGroupDiscoveryFailed(err error) bool {
+	_, ok := err.(*groupDiscoveryFailedError)
+	return ok
+}
+
+// listableResources discovers all resources which can be listed, in their
+// groups' preferred versions. See discoverAPIResources for unavailable group
+// versions.
+func (c *RESTClient) listableResources() ([]GroupVersionResource, error) {
+	return c.discoverResources(false, "list")
+}
+
+// discoverResources discovers all resources which support all the verbs, in
+// their groups' preferred versions, optionally only the namespaced ones. See
+// discoverAPIResources for unavailable group versions.
+func (c *RESTClient) discoverResources(namespacedOnly bool, verbs ...string) ([]GroupVersionResource, error) {
+	resources, err := c.discoverAPIResources(verbs...)
+	if err != nil && !isGroupDiscoveryFailed(err) {
+		return nil, err
+	}
+	gvrs := []GroupVersionResource{}
+	for _, resource := range resources {
+		if namespacedOnly && !resource.Namespaced {
+			continue
+		}
+		gvrs = append(gvrs, resource.GVR)
+	}
+	return gvrs, err
+}
+
+// discoverAPIResources discovers all resources which support all the verbs,
+// in their groups' preferred versions. Group versions the APIServer responds
+// to with 503 Service Unavailable are skipped, and reported by a
+// groupDiscoveryFailedError, which is returned along with the resources of all
+// other group versions.
+func (c *RESTClient) discoverAPIResources(verbs ...string) ([]apiResource, error) {
+	groupVersions := []string{"v1"}
+	groups := apiGroupList{}
+	if err := c.Do("GET", "/apis", nil, &groups); err != nil {
+		return nil, err
+	}
+	for _, group := range groups.Groups {
+		groupVersions = append(groupVersions, group.PreferredVersion.GroupVersion)
+	}
+
+	resources := []apiResource{}
+	unavailable := []string{}
+	for _, groupVersion := range groupVersions {
+		discoveryPath := path.Join("/apis", groupVersion)
+		if groupVersion == "v1" {
+			discoveryPath = "/api/v1"
+		}
+		list := apiResourceList{}
+		err := c.Do("GET", discoveryPath, nil, &list)
+		if IsNotFound(err) {
+			// The group is gone, e.g. because the CustomResourceDefinition
+			// has just been deleted.
+			continue
+		}
+		if statusErr, ok := err.(*StatusError); ok && statusErr.Code == http.StatusServiceUnavailable {
+			unavailable = append(unavailable, groupVersion)
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		group, version := "", groupVersion
+		if i := strings.Index(groupVersion, "/"); i >= 0 {
+			group, version = groupVersion[:i], groupVersion[i+1:]
+		}
+	resources:
+		for _, resource := range list.Resources {
+			if strings.Contains(resource.Name, "/") {
+				continue
+			}
+			for _, verb := range verbs {
+				if !containsString(resource.Verbs, verb) {
+					continue resources
+				}
+			}
+			resources = append(resources, apiResource{
+				GVR:        GroupVersionResource{Group: group, Version: version, Resource: resource.Name},
+				Kind:       resource.Kind,
+				Namespaced: resource.Namespaced,
+			})
+		}
+	}
+	if len(unavailable) > 0 {
+		return resources, &groupDiscoveryFailedError{groupVersions: unavailable}
+	}
+	return resources, nil
+}
+
+// staleCaches returns the resources for which a list served from the watch
+// cache (resourceVersion=0) differs from a list served from Etcd.
+func (c *RESTClient) staleCaches(resources []GroupVersionResource) ([]GroupVersionResource, error) {
+	var (
+		mu       sync.Mutex
+		wg       sync.WaitGroup
+		stale    = []GroupVersionResource{}
+		firstErr error
+	)
+
+	for _, gvr := range resources {
+		wg.Add(1)
+		go func(gvr GroupVersionResource) {
+			defer wg.Done()
+
+			cached, err := c.listNames(gvr, "?resourceVersion=0")
+			if err == nil {
+				var current map[string]bool
+				current, err = c.listNames(gvr, "")
+				if err == nil && !sameNames(cached, current) {
+					mu.Lock()
+					stale = append(stale, gvr)
+					mu.Unlock()
+				}
+			}
+			if err != nil && !IsNotFound(err) {
+				mu.Lock()
+				if firstErr == nil {
+					firstErr = err
+				}
+				mu.Unlock()
+			}
+		}(gvr)
+	}
+	wg.Wait()
+
+	return stale, firstErr
+}
+
+// listNames lists the resource in all namespaces and returns the
+// "namespace/name" of all items.
+func (c *RESTClient) listNames(gvr GroupVersionResource, query string) (map[string]bool, error) {
+	list := Object{}
+	if err := c.Do("GET", c.ResourcePath(gvr, "", "")+query, nil, &list); err != nil {
+		return nil, err
+	}
+	names := map[string]bool{}
+	for _, item := range list.Items() {
+		names[item.Namespace()+"/"+item.Name()] = true
+	}
+	return names, nil
+}
+
+func sameNames(a, b map[string]bool) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for name := range a {
+		if !b[name] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,126 @@
+package integration
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/ghttp"
+)
+
+var _ = Describe("Reset", func() {
+	It("needs a started ControlPlane", func() {
+		Expect((&ControlPlane{}).Reset()).To(MatchError(ContainSubstring("needs to be started")))
+	})
+
+	It("keeps the baseline and bootstrap objects", func() {
+		cp := &ControlPlane{resetBaseline: map[string]bool{"/registry/configmaps/default/fixture": true}}
+
+		Expect(cp.keepOnReset("/registry/configmaps/default/fixture")).To(BeTrue())
+		Expect(cp.keepOnReset("/registry/namespaces/kube-system")).To(BeTrue())
+		Expect(cp.keepOnReset("/registry/clusterroles/system:basic-user")).To(BeTrue())
+		Expect(cp.keepOnReset("/registry/clusterroles/edit")).To(BeTrue())
+		Expect(cp.keepOnReset("/registry/services/specs/default/kubernetes")).To(BeTrue())
+		Expect(cp.keepOnReset("/registry/masterleases/127.0.0.1")).To(BeTrue())
+
+		Expect(cp.keepOnReset("/registry/configmaps/default/created-by-a-spec")).To(BeFalse())
+		Expect(cp.keepOnReset("/registry/namespaces/my-ns")).To(BeFalse())
+		Expect(cp.keepOnReset("/registry/clusterroles/my-role")).To(BeFalse())
+		Expect(cp.keepOnReset("/registry/namespaces/default-foo")).To(BeFalse())
+		Expect(cp.keepOnReset("/registry/namespaces/kube-system-x")).To(BeFalse())
+		Expect(cp.keepOnReset("/registry/clusterroles/editor")).To(BeFalse())
+		Expect(cp.keepOnReset("/registry/clusterroles/viewer")).To(BeFalse())
+		Expect(cp.keepOnReset("/registry/clusterroles/admin-team")).To(BeFalse())
+		Expect(cp.keepOnReset("/registry/clusterrolebindings/cluster-admin-team")).To(BeFalse())
+		Expect(cp.keepOnReset("/registry/services/specs/default/kubernetes-api")).To(BeFalse())
+		Expect(cp.keepOnReset("/registry/services/endpoints/default/kubernetes-api")).To(BeFalse())
+	})
+
+	It("keeps the APIServices of the baseline and of AggregatedAPIServers only", func() {
+		cp := &ControlPlane{
+			resetBaseline:        map[string]bool{"/registry/apiregistration.k8s.io/apiservices/v1.apps": true},
+			aggregatedAPIServers: []*AggregatedAPIServer{{Group: "wardle.example.com", Versions: []string{"v1alpha1"}}},
+		}
+
+		Expect(cp.keepOnReset("/registry/apiregistration.k8s.io/apiservices/v1.apps")).To(BeTrue())
+		Expect(cp.keepOnReset("/registry/apiregistration.k8s.io/apiservices/v1alpha1.wardle.example.com")).To(BeTrue())
+		Expect(cp.keepOnReset("/registry/services/specs/kube-system/integration-wardle-example-com")).To(BeTrue())
+
+		Expect(cp.keepOnReset("/registry/apiregistration.k8s.io/apiservices/v1.widgets.example.com")).To(BeFalse())
+	})
+
+	Describe("waiting for the watch caches", func() {
+		var (
+			server *ghttp.Server
+			client *RESTClient
+		)
+
+		BeforeEach(func() {
+			server = ghttp.NewServer()
+			serverURL, err := url.Parse(server.URL())
+			Expect(err).NotTo(HaveOccurred())
+			client = &RESTClient{URL: *serverURL, httpClient: http.DefaultClient}
+
+			server.RouteToHandler("GET", "/apis", ghttp.RespondWith(http.StatusOK, `{
+				"groups": [
+					{"preferredVersion": {"groupVersion": "apps/v1"}},
+					{"preferredVersion": {"groupVersion": "gone.example.com/v1"}},
+					{"preferredVersion": {"groupVersion": "wardle.example.com/v1alpha1"}}
+				]
+			}`))
+			server.RouteToHandler("GET", "/api/v1", ghttp.RespondWith(http.StatusOK, `{
+				"groupVersion": "v1",
+				"resources": [
+					{"name": "configmaps", "verbs": ["create", "get", "list", "watch"]},
+					{"name": "pods/log", "verbs": ["get"]},
+					{"name": "bindings", "verbs": ["create"]}
+				]
+			}`))
+			server.RouteToHandler("GET", "/apis/apps/v1", ghttp.RespondWith(http.StatusOK, `{
+				"groupVersion": "apps/v1",
+				"resources": [{"name": "deployments", "verbs": ["list"]}]
+			}`))
+			server.RouteToHandler("GET", "/apis/gone.example.com/v1", ghttp.RespondWith(http.StatusNotFound, `{"kind": "Status", "reason": "NotFound"}`))
+			server.RouteToHandler("GET", "/apis/wardle.example.com/v1alpha1", ghttp.RespondWith(http.StatusServiceUnavailable, "service unavailable"))
+		})
+		AfterEach(func() {
+			server.Close()
+		})
+
+		It("discovers the resources which can be listed, and reports unavailable group versions", func() {
+			resources, err := client.listableResources()
+			Expect(err).To(Equal(&groupDiscoveryFailedError{groupVersions: []string{"wardle.example.com/v1alpha1"}}))
+			Expect(resources).To(ConsistOf(
+				GroupVersionResource{Version: "v1", Resource: "configmaps"},
+				GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"},
+			))
+		})
+
+		It("finds caches which serve other objects than etcd", func() {
+			list := func(names ...string) string {
+				items := []string{}
+				for _, name := range names {
+					items = append(items, `{"metadata": {"namespace": "default", "name": "`+name+`"}}`)
+				}
+				return `{"items": [` + strings.Join(items, ",") + `]}`
+			}
+			server.RouteToHandler("GET", "/api/v1/configmaps", func(rw http.ResponseWriter, req *http.Request) {
+				if req.URL.Query().Get("resourceVersion") == "0" {
+					rw.Write([]byte(list("kept", "deleted")))
+					return
+				}
+				rw.Write([]byte(list("kept")))
+			})
+			server.RouteToHandler("GET", "/apis/apps/v1/deployments", ghttp.RespondWith(http.StatusOK, list("web")))
+
+			stale, err := client.staleCaches([]GroupVersionResource{
+				{Version: "v1", Resource: "configmaps"},
+				{Group: "apps", Version: "v1", Resource: "deployments"},
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(stale).To(ConsistOf(GroupVersionResource{Version: "v1", Resource: "configmaps"}))
+		})
+	})
+})
@@ -0,0 +1,306 @@
+package integration
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+)
+
+// GroupVersionResource identifies a resource of the API, e.g.
+//
+//	GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"}
+//
+// The Group is empty for the core group. The Resource can be followed by a
+// subresource, e.g. "deployments/status" or "deployments/scale".
+type GroupVersionResource struct {
+	Group    string
+	Version  string
+	Resource string
+}
+
+func (gvr GroupVersionResource) String() string {
+	return path.Join(gvr.Group, gvr.Version, gvr.Resource)
+}
+
+// The content types of the patches RESTClient.Patch can send.
+const (
+	JSONPatchType           = "application/json-patch+json"
+	MergePatchType          = "application/merge-patch+json"
+	StrategicMergePatchType = "application/strategic-merge-patch+json"
+)
+
+// RESTClient is a minimal client for the API of a ControlPlane, which works
+// with any resource as an Object. It talks to the secure URL of the
+// APIServer, trusting its CA and authenticating with a client certificate.
+//
+// For anything not covered by its methods, use Do.
+type RESTClient struct {
+	// URL is the URL of the APIServer.
+	URL url.URL
+
+	httpClient *http.Client
+}
+
+// RESTClient returns a RESTClient authenticating as the AdminUser.
+func (f *ControlPlane) RESTClient() (*RESTClient, error) {
+	return f.RESTClientAs(AdminUser)
+}
+
+// RESTClientAs returns a RESTClient authenticating as the user.
+func (f *ControlPlane) RESTClientAs(user User) (*RESTClient, error) {
+	tlsConfig, err := f.APIServer.clientTLSConfig(user)
+	if err != nil {
+		return nil, err
+	}
+	return &RESTClient{
+		URL: *f.SecureAPIURL(),
+		httpClient: &http.Client{
+			Transport: &http.Transport{TLSClientConfig: tlsConfig},
+		},
+	}, nil
+}
+
+// clientTLSConfig returns the TLS configuration for a client which trusts the
+// APIServer's CA and authenticates as the user.
+func (s *APIServer) clientTLSConfig(user User) (*tls.Config, error) {
+	cert, key, err := s.clientCert(user)
+	if err != nil {
+		return nil, err
+	}
+	keyPair, err := tls.X509KeyPair(cert, key)
+	if err != nil {
+		return nil, err
+	}
+
+	pool := x509.NewCertPool()
+	pool.AppendCertsFromPEM(s.ca.CertBytes())
+
+	return &tls.Config{
+		RootCAs:      pool,
+		Certificates: []tls.Certificate{keyPair},
+	}, nil
+}
+
+// Get returns the object with the name in the namespace. Pass "" as the
+// namespace for cluster scoped resources, as it is part of the path
+// otherwise.
+func (c *RESTClient) Get(gvr GroupVersionResource, namespace, name string) (Object, error) {
+	object := Object{}
+	err := c.Do("GET", c.ResourcePath(gvr, namespace, name), nil, &object)
+	return object, err
+}
+
+// List returns a list of all objects of the resource in the namespace, or in
+// all namespaces if namespace is empty.
+func (c *RESTClient) List(gvr GroupVersionResource, namespace string) (Object, error) {
+	list := Object{}
+	err := c.Do("GET", c.ResourcePath(gvr, namespace, ""), nil, &list)
+	return list, err
+}
+
+// Create creates the object and returns it, as it was stored.
+func (c *RESTClient) Create(gvr GroupVersionResource, namespace string, object Object) (Object, error) {
+	created := Object{}
+	err := c.Do("POST", c.ResourcePath(gvr, namespace, ""), object, &created)
+	return created, err
+}
+
+// Update replaces the object with the object's name and returns it, as it was
+// stored. If the object has a metadata.resourceVersion, the update fails with
+// a Conflict if the object has been changed since.
+func (c *RESTClient) Update(gvr GroupVersionResource, namespace string, object Object) (Object, error) {
+	updated := Object{}
+	err := c.Do("PUT", c.ResourcePath(gvr, namespace, object.Name()), object, &updated)
+	return updated, err
+}
+
+// Patch patches the object with the name and returns it, as it was stored.
+// The patchType is one of JSONPatchType, MergePatchType or
+// StrategicMergePatchType.
+func (c *RESTClient) Patch(gvr GroupVersionResource, namespace, name, patchType string, patch []byte) (Object, error) {
+	patched := Object{}
+	err := c.do("PATCH", c.ResourcePath(gvr, namespace, name), patchType, bytes.NewReader(patch), &patched)
+	return patched, err
+}
+
+// Delete deletes the object with the name. It does not wait for the object to
+// be gone, e.g. if it has finalizers.
+func (c *RESTClient) Delete(gvr GroupVersionResource, namespace, name string) error {
+	return c.Do("DELETE", c.ResourcePath(gvr, namespace, name), nil, nil)
+}
+
+// ResourcePath returns the path of the resource in the namespace, and of the
+// object with the name if it is not empty, e.g.
+// "/apis/apps/v1/namespaces/default/deployments/my-deployment/scale".
+func (c *RESTClient) ResourcePath(gvr GroupVersionResource, namespace, name string) string {
+	segments := []string{"/apis", gvr.Group, gvr.Version}
+	if gvr.Group == "" {
+		segments = []string{"/api", gvr.Version}
+	}
+	if namespace != "" {
+		segments = append(segments, "namespaces", namespace)
+	}
+
+	resource, subresource := gvr.Resource, ""
+	if i := strings.Index(resource, "/"); i >= 0 {
+		resource, subresource = resource[:i], resource[i+1:]
+	}
+	segments = append(segments, resource)
+	if name != "" {
+		segments = append(segments, name)
+	}
+	if subresource != "" {
+		segments = append(segments, subresource)
+	}
+
+	return path.Join(segments...)
+}
+
+// Do sends a request with the body, encoded as JSON, to the path of the
+// APIServer, and decodes the response into into. The body and into can be
+// nil. If the APIServer does not respond with a 2xx status code, the error is
+// a *StatusError.
+func (c *RESTClient) Do(method, path string, body, into interface{}) error {
+	var reqBody io.Reader
+	if body != nil {
+		content, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reqBody = bytes.NewReader(content)
+	}
+	return c.do(method, path, "application/json", reqBody, into)
+}
+
+func (c *RESTClient) do(method, path, contentType string, body io.Reader, into interface{}) error {
+	res, err := c.send(context.Background(), method, path, contentType, body)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	resBody, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return err
+	}
+	if into == nil {
+		return nil
+	}
+	return json.Unmarshal(resBody, into)
+}
+
+// send sends the request and returns the response, with its body still to be
+// read and closed. Responses without a 2xx status code are turned into a
+// *StatusError instead.
+func (c *RESTClient) send(ctx context.Context, method, path, contentType string, body io.Reader) (*http.Response, error) {
+	u := c.URL
+	u.Path = path
+	if i := strings.Index(path, "?"); i >= 0 {
+		u.Path, u.RawQuery = path[:i], path[i+1:]
+	}
+
+	req, err := http.NewRequest(method, u.String(), body)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	res, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		defer res.Body.Close()
+		resBody, err := ioutil.ReadAll(res.Body)
+		if err != nil {
+			return nil, err
+		}
+		return nil, newStatusError(method, path, res.StatusCode, resBody)
+	}
+	return res, nil
+}
+
+// StatusError is returned by the RESTClient when the APIServer responds with
+// an error. It carries the details of the Status the APIServer responded
+// with, if any.
+type StatusError struct {
+	// Code is the HTTP status code of the response.
+	Code int
+
+	// Reason is a machine readable description of the error, e.g. "NotFound"
+	// or "AlreadyExists".
+	Reason string
+
+	// Message is a human readable description of the error.
+	Message string
+
+	// Details are the details of the Status, if any, e.g. the causes of an
+	// "Invalid" error.
+	Details Object
+
+	method string
+	path   string
+}
+
+type status struct {
+	Kind    string `json:"kind"`
+	Code    int    `json:"code"`
+	Reason  string `json:"reason"`
+	Message string `json:"message"`
+	Details Object `json:"details"`
+}
+
+func newStatusError(method, path string, code int, body []byte) *StatusError {
+	statusErr := &StatusError{Code: code, method: method, path: path}
+
+	s := status{}
+	if err := json.Unmarshal(body, &s); err == nil && s.Kind == "Status" {
+		statusErr.Reason = s.Reason
+		statusErr.Message = s.Message
+		statusErr.Details = s.Details
+	} else {
+		statusErr.Message = strings.TrimSpace(string(body))
+	}
+	return statusErr
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("%s %s failed with %d %s: %s", e.method, e.path, e.Code, e.Reason, e.Message)
+}
+
+// IsNotFound returns true if the error is a StatusError with the reason
+// "NotFound".
+func IsNotFound(err error) bool {
+	return hasReason(err, "NotFound")
+}
+
+// IsAlreadyExists returns true if the error is a StatusError with the reason
+// "AlreadyExists".
+func IsAlreadyExists(err error) bool {
+	return hasReason(err, "AlreadyExists")
+}
+
+// IsConflict returns true if the error is a StatusError with the reason
+// "Conflict".
+func IsConflict(err error) bool {
+	return hasReason(err, "Conflict")
+}
+
+func hasReason(err error, reason string) bool {
+	statusErr, ok := err.(*StatusError)
+	return ok && statusErr.Reason == reason
+}
@@ -0,0 +1,141 @@
+package integration
+
+import (
+	"net/http"
+	"net/url"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/ghttp"
+
+	"github.com/kubernetes-sigs/testing_frameworks/integration/internal"
+)
+
+var _ = Describe("RESTClient", func() {
+	var (
+		server *ghttp.Server
+		client *RESTClient
+	)
+
+	deployments := GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"}
+	pods := GroupVersionResource{Version: "v1", Resource: "pods"}
+
+	BeforeEach(func() {
+		server = ghttp.NewServer()
+		serverURL, err := url.Parse(server.URL())
+		Expect(err).NotTo(HaveOccurred())
+		client = &RESTClient{URL: *serverURL, httpClient: http.DefaultClient}
+	})
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("builds resource paths", func() {
+		Expect(client.ResourcePath(pods, "default", "")).To(Equal("/api/v1/namespaces/default/pods"))
+		Expect(client.ResourcePath(pods, "", "")).To(Equal("/api/v1/pods"))
+		Expect(client.ResourcePath(deployments, "default", "web")).To(Equal("/apis/apps/v1/namespaces/default/deployments/web"))
+
+		scale := GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments/scale"}
+		Expect(client.ResourcePath(scale, "default", "web")).To(Equal("/apis/apps/v1/namespaces/default/deployments/web/scale"))
+
+		namespaces := GroupVersionResource{Version: "v1", Resource: "namespaces"}
+		Expect(client.ResourcePath(namespaces, "", "kube-system")).To(Equal("/api/v1/namespaces/kube-system"))
+	})
+
+	It("gets objects", func() {
+		server.AppendHandlers(ghttp.CombineHandlers(
+			ghttp.VerifyRequest("GET", "/apis/apps/v1/namespaces/default/deployments/web"),
+			ghttp.VerifyHeaderKV("Accept", "application/json"),
+			ghttp.RespondWith(http.StatusOK, `{"kind": "Deployment", "metadata": {"name": "web"}}`),
+		))
+
+		deployment, err := client.Get(deployments, "default", "web")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(deployment.Kind()).To(Equal("Deployment"))
+	})
+
+	It("creates and updates objects", func() {
+		server.AppendHandlers(
+			ghttp.CombineHandlers(
+				ghttp.VerifyRequest("POST", "/api/v1/namespaces/default/pods"),
+				ghttp.VerifyContentType("application/json"),
+				ghttp.VerifyJSON(`{"metadata": {"name": "my-pod"}}`),
+				ghttp.RespondWith(http.StatusCreated, `{"metadata": {"name": "my-pod", "uid": "1234"}}`),
+			),
+			ghttp.CombineHandlers(
+				ghttp.VerifyRequest("PUT", "/api/v1/namespaces/default/pods/my-pod"),
+				ghttp.RespondWith(http.StatusOK, `{"metadata": {"name": "my-pod", "uid": "1234"}}`),
+			),
+		)
+
+		created, err := client.Create(pods, "default", Object{"metadata": map[string]interface{}{"name": "my-pod"}})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(created).To(HaveField("metadata.uid", "1234"))
+
+		_, err = client.Update(pods, "default", created)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("patches objects with the patch type", func() {
+		server.AppendHandlers(ghttp.CombineHandlers(
+			ghttp.VerifyRequest("PATCH", "/apis/apps/v1/namespaces/default/deployments/web"),
+			ghttp.VerifyContentType(MergePatchType),
+			ghttp.VerifyBody([]byte(`{"spec": {"replicas": 2}}`)),
+			ghttp.RespondWith(http.StatusOK, `{"spec": {"replicas": 2}}`),
+		))
+
+		patched, err := client.Patch(deployments, "default", "web", MergePatchType, []byte(`{"spec": {"replicas": 2}}`))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(patched).To(HaveField("spec.replicas", 2))
+	})
+
+	It("passes on query parameters", func() {
+		server.AppendHandlers(ghttp.CombineHandlers(
+			ghttp.VerifyRequest("GET", "/api/v1/pods", "labelSelector=app%3Dweb"),
+			ghttp.RespondWith(http.StatusOK, `{"items": []}`),
+		))
+
+		Expect(client.Do("GET", "/api/v1/pods?labelSelector=app%3Dweb", nil, nil)).To(Succeed())
+	})
+
+	It("returns StatusErrors", func() {
+		server.AppendHandlers(
+			ghttp.RespondWith(http.StatusNotFound, `{
+				"kind": "Status",
+				"code": 404,
+				"reason": "NotFound",
+				"message": "pods \"missing\" not found",
+				"details": {"name": "missing", "kind": "pods"}
+			}`),
+			ghttp.RespondWith(http.StatusInternalServerError, "something broke"),
+		)
+
+		err := client.Delete(pods, "default", "missing")
+		Expect(IsNotFound(err)).To(BeTrue())
+		Expect(IsAlreadyExists(err)).To(BeFalse())
+		statusErr := err.(*StatusError)
+		Expect(statusErr.Code).To(Equal(404))
+		Expect(statusErr.Details).To(HaveField("name", "missing"))
+		Expect(err).To(MatchError(ContainSubstring(`DELETE /api/v1/namespaces/default/pods/missing failed with 404 NotFound: pods "missing" not found`)))
+
+		_, err = client.Get(pods, "default", "broken")
+		Expect(err).To(MatchError(ContainSubstring("something broke")))
+		Expect(err.(*StatusError).Code).To(Equal(500))
+	})
+
+	It("needs a started APIServer to issue credentials", func() {
+		cp := &ControlPlane{APIServer: &APIServer{}}
+		_, err := cp.RESTClient()
+		Expect(err).To(MatchError(ContainSubstring("needs to be started")))
+	})
+
+	It("trusts the APIServer's CA and presents a client certificate", func() {
+		ca, err := internal.NewTinyCA("test-ca")
+		Expect(err).NotTo(HaveOccurred())
+
+		tlsConfig, err := (&APIServer{ca: ca}).clientTLSConfig(AdminUser)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(tlsConfig.Certificates).To(HaveLen(1))
+		Expect(tlsConfig.RootCAs.Subjects()).To(HaveLen(1))
+	})
+})
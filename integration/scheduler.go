@@ -0,0 +1,183 @@
+package integration
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/url"
+	"path/filepath"
+	"time"
+
+	"github.com/kubernetes-sigs/testing_frameworks/integration/internal"
+)
+
+// Scheduler knows how to run a kube-scheduler, which assigns Pods to Nodes.
+// Use it to test scheduler extenders or scheduler profiles.
+//
+// Configure one on a ControlPlane to have it started after, and stopped
+// before, the APIServer, with a kubeconfig to connect to it.
+type Scheduler struct {
+	// SecureURL is the address the Scheduler should serve its health checks
+	// on. It is served over TLS, with a certificate issued by a CA generated
+	// when the Scheduler starts.
+	//
+	// If this is not specified, we default to a random free port on localhost.
+	SecureURL *url.URL
+
+	// Path is the path to the kube-scheduler binary.
+	//
+	// If this is left as the empty string, we will attempt to locate a binary,
+	// by checking for the TEST_ASSET_KUBE_SCHEDULER environment variable, and
+	// the default test assets directory. See the "Binaries" section above (in
+	// doc.go) for details.
+	Path string
+
+	// Args is a list of arguments which will passed to the Scheduler binary.
+	// Before they are passed on, they will be evaluated as go-template
+	// strings. This means you can use fields which are defined and exported on
+	// this Scheduler struct (e.g. "--config={{ .ConfigFile }}").
+	// Those templates will be evaluated after the defaulting of the
+	// Scheduler's fields has already happened and just before the binary
+	// actually gets started.
+	//
+	// If not specified, the minimal set of arguments to run the Scheduler will
+	// be used.
+	Args []string
+
+	// Config is the Scheduler's configuration file, e.g. a
+	// KubeSchedulerConfiguration with the profiles or extenders under test.
+	// Like the Args, it is evaluated as a go-template, so it can refer to e.g.
+	// "{{ .KubeConfigFile }}", and written to ConfigFile() before the
+	// Scheduler starts.
+	//
+	// If not specified, a minimal configuration, which connects to the
+	// APIServer with the KubeConfigFile, will be used. Its apiVersion might
+	// need to be adapted to the version of the kube-scheduler binary.
+	Config string
+
+	// CertDir is a path to a directory containing the Scheduler's serving
+	// certificate and its configuration file.
+	//
+	// If left unspecified, then the Start() method will create a fresh temporary
+	// directory, and the Stop() method will clean it up.
+	CertDir string
+
+	// KubeConfigFile is the path to the kubeconfig the Scheduler uses to
+	// connect to the APIServer.
+	//
+	// If this is not specified, the Start() method will return an error. A
+	// ControlPlane generates one, authenticating as "system:kube-scheduler".
+	KubeConfigFile string
+
+	// StartTimeout, StopTimeout specify the time the Scheduler is allowed to
+	// take when starting and stopping before an error is emitted.
+	//
+	// If not specified, these default to 20 seconds.
+	StartTimeout time.Duration
+	StopTimeout  time.Duration
+
+	// Out, Err specify where Scheduler should write its StdOut, StdErr to.
+	//
+	// If not specified, the output will be discarded.
+	Out io.Writer
+	Err io.Writer
+
+	processState *internal.ProcessState
+	ca           *internal.TinyCA
+}
+
+// Start starts the scheduler, waits for it to come up, and returns an error,
+// if occurred.
+func (s *Scheduler) Start() error {
+	if s.KubeConfigFile == "" {
+		return fmt.Errorf("expected KubeConfigFile to be configured")
+	}
+
+	var err error
+	if s.SecureURL == nil {
+		s.SecureURL, err = internal.NewLocalURL("https")
+		if err != nil {
+			return err
+		}
+	}
+
+	s.processState = &internal.ProcessState{}
+
+	s.processState.DefaultedProcessInput, err = internal.DoDefaulting(
+		"kube-scheduler",
+		s.SecureURL,
+		s.CertDir,
+		s.Path,
+		s.StartTimeout,
+		s.StopTimeout,
+	)
+	if err != nil {
+		return err
+	}
+
+	s.CertDir = s.processState.Dir
+	s.Path = s.processState.Path
+	s.StartTimeout = s.processState.StartTimeout
+	s.StopTimeout = s.processState.StopTimeout
+
+	if s.ca == nil {
+		s.ca, err = internal.NewTinyCA("integration-scheduler-ca")
+		if err != nil {
+			return err
+		}
+	}
+	err = internal.WriteServingCert(s.CertDir, s.ca,
+		internal.SchedulerServingCertFile, internal.SchedulerServingKeyFile,
+		s.SecureURL.Hostname())
+	if err != nil {
+		return err
+	}
+
+	config, err := internal.RenderConfig(internal.DoSchedulerConfigDefaulting(s.Config), s)
+	if err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(s.ConfigFile(), config, 0600); err != nil {
+		return err
+	}
+
+	s.processState.HealthCheckEndpoint = "/healthz"
+	s.processState.HealthCheckClient = healthCheckClient(s.ca)
+
+	s.processState.Args, err = internal.RenderTemplates(
+		internal.DoSchedulerArgDefaulting(s.Args), s,
+	)
+	if err != nil {
+		return err
+	}
+
+	return s.processState.Start(s.Out, s.Err)
+}
+
+// ConfigFile returns the path the Config is written to. It is empty if the
+// Scheduler has not been started yet.
+func (s *Scheduler) ConfigFile() string {
+	if s.CertDir == "" {
+		return ""
+	}
+	return filepath.Join(s.CertDir, internal.SchedulerConfigFile)
+}
+
+// Stop stops this process gracefully, waits for its termination, and cleans up
+// the CertDir if necessary.
+func (s *Scheduler) Stop() error {
+	if s.processState == nil {
+		return nil
+	}
+	return s.processState.Stop()
+}
+
+// restart stops the Scheduler, calls whileStopped, and starts it again, e.g.
+// to have it start over after the APIServer's state changed under its
+// caches.
+func (s *Scheduler) restart(whileStopped func() error) error {
+	if s.processState == nil {
+		return whileStopped()
+	}
+	return s.processState.Restart(s.Out, s.Err, whileStopped)
+}
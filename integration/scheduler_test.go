@@ -0,0 +1,59 @@
+package integration
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/kubernetes-sigs/testing_frameworks/integration/internal"
+)
+
+var _ = Describe("Scheduler", func() {
+	It("needs a kubeconfig", func() {
+		Expect((&Scheduler{}).Start()).To(MatchError(ContainSubstring("KubeConfigFile")))
+	})
+
+	It("can be stopped without having been started", func() {
+		Expect((&Scheduler{}).Stop()).To(Succeed())
+	})
+
+	Context("when started", func() {
+		var s *Scheduler
+
+		BeforeEach(func() {
+			s = &Scheduler{
+				Path:           "/does/not/exist/kube-scheduler",
+				KubeConfigFile: "/some/kubeconfig",
+			}
+		})
+		AfterEach(func() {
+			os.RemoveAll(s.CertDir)
+		})
+
+		It("is configured to serve its health checks securely, and to connect to the APIServer", func() {
+			Expect(s.Start()).NotTo(Succeed())
+
+			Expect(s.SecureURL.Scheme).To(Equal("https"))
+			Expect(filepath.Join(s.CertDir, internal.SchedulerServingCertFile)).To(BeAnExistingFile())
+			Expect(s.processState.HealthCheckEndpoint).To(Equal("/healthz"))
+			Expect(s.processState.Args).To(ContainElement("--config=" + s.ConfigFile()))
+			Expect(s.processState.Args).To(ContainElement("--secure-port=" + s.SecureURL.Port()))
+
+			config, err := ioutil.ReadFile(s.ConfigFile())
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(config)).To(ContainSubstring("kubeconfig: /some/kubeconfig"))
+		})
+
+		It("renders the configured Config", func() {
+			s.Config = "kind: KubeSchedulerConfiguration\nclientConnection:\n  kubeconfig: {{ .KubeConfigFile }}\nprofiles: []\n"
+			Expect(s.Start()).NotTo(Succeed())
+
+			config, err := ioutil.ReadFile(s.ConfigFile())
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(config)).To(Equal("kind: KubeSchedulerConfiguration\nclientConnection:\n  kubeconfig: /some/kubeconfig\nprofiles: []\n"))
+		})
+	})
+})
@@ -0,0 +1,109 @@
+package integration
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"time"
+)
+
+// serviceAccountTokenValidity is how long the tokens requested by
+// ServiceAccountToken are valid.
+const serviceAccountTokenValidity = time.Hour
+
+// ServiceAccountToken requests a token for the ServiceAccount in the
+// namespace from the TokenRequest API of the APIServer. The ServiceAccount is
+// created, if it does not exist yet.
+func (f *ControlPlane) ServiceAccountToken(namespace, name string) (string, error) {
+	kubeCtl := f.KubeCtl()
+
+	existing, err := kubeCtl.runForOutput(
+		"get", "serviceaccount", name, "--namespace", namespace,
+		"--ignore-not-found", "--output", "name",
+	)
+	if err != nil {
+		return "", err
+	}
+	if existing == "" {
+		if _, err := kubeCtl.runForOutput("create", "serviceaccount", name, "--namespace", namespace); err != nil {
+			return "", err
+		}
+	}
+
+	requestFile, err := writeTokenRequest()
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(requestFile)
+
+	out, err := kubeCtl.runForOutput(
+		"create", "--raw", fmt.Sprintf("/api/v1/namespaces/%s/serviceaccounts/%s/token", namespace, name),
+		"--filename", requestFile,
+	)
+	if err != nil {
+		return "", err
+	}
+
+	response := tokenRequest{}
+	if err := json.Unmarshal([]byte(out), &response); err != nil {
+		return "", err
+	}
+	if response.Status == nil || response.Status.Token == "" {
+		return "", fmt.Errorf("the APIServer returned no token for ServiceAccount %s/%s", namespace, name)
+	}
+	return response.Status.Token, nil
+}
+
+// KubeCtlForServiceAccount returns a KubeCtl which connects to the secure port
+// of this ControlPlane and authenticates with a token of the ServiceAccount in
+// the namespace. See ServiceAccountToken for details.
+func (f *ControlPlane) KubeCtlForServiceAccount(namespace, name string) (*KubeCtl, error) {
+	token, err := f.ServiceAccountToken(namespace, name)
+	if err != nil {
+		return nil, err
+	}
+	return f.KubeCtlWithToken(token), nil
+}
+
+type tokenRequest struct {
+	APIVersion string              `json:"apiVersion"`
+	Kind       string              `json:"kind"`
+	Spec       tokenRequestSpec    `json:"spec"`
+	Status     *tokenRequestStatus `json:"status,omitempty"`
+}
+
+type tokenRequestSpec struct {
+	ExpirationSeconds int64 `json:"expirationSeconds"`
+}
+
+type tokenRequestStatus struct {
+	Token string `json:"token"`
+}
+
+// writeTokenRequest writes a TokenRequest for a token, which is valid for
+// serviceAccountTokenValidity, into a temporary file, and returns its path.
+func writeTokenRequest() (string, error) {
+	content, err := json.Marshal(tokenRequest{
+		APIVersion: "authentication.k8s.io/v1",
+		Kind:       "TokenRequest",
+		Spec: tokenRequestSpec{
+			ExpirationSeconds: int64(serviceAccountTokenValidity / time.Second),
+		},
+	})
+	if err != nil {
+		return "", err
+	}
+
+	file, err := ioutil.TempFile("", "k8s_test_framework_")
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	if _, err := file.Write(content); err != nil {
+		os.Remove(file.Name())
+		return "", err
+	}
+	return file.Name(), nil
+}
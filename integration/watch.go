@@ -0,0 +1,436 @@
+package integration
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/onsi/gomega/format"
+	"github.com/onsi/gomega/types"
+)
+
+// The types of WatchEvents.
+const (
+	EventAdded    = "ADDED"
+	EventModified = "MODIFIED"
+	EventDeleted  = "DELETED"
+)
+
+// watchReconnectInterval is the time a Watcher waits before reconnecting
+// after its watch ended.
+var watchReconnectInterval = 100 * time.Millisecond
+
+// WatchOptions restrict the changes a Watcher receives.
+type WatchOptions struct {
+	// ResourceVersion is the version after which changes are received. If
+	// empty, the Watcher lists the objects first, receives an ADDED event for
+	// each of them, and watches from the version of the list.
+	ResourceVersion string
+
+	// LabelSelector and FieldSelector restrict the objects changes are
+	// received for, e.g. "app=web" or "metadata.name=web".
+	LabelSelector string
+	FieldSelector string
+}
+
+// WatchEvent is a change of an object received by a Watcher.
+type WatchEvent struct {
+	// Type is one of EventAdded, EventModified or EventDeleted.
+	Type string `json:"type"`
+
+	// Object is the object after the change, or its last state before it was
+	// deleted.
+	Object Object `json:"object"`
+}
+
+func (e WatchEvent) String() string {
+	return fmt.Sprintf("%s %s %s/%s", e.Type, e.Object.Kind(), e.Object.Namespace(), e.Object.Name())
+}
+
+// Watcher receives the changes of the objects of a resource. It reconnects
+// when the APIServer ends the watch, continuing after the last change it
+// received. If that version is too old, it lists the objects again, and
+// receives the changes between the objects it knew about and the list, so
+// no change is received twice.
+//
+// Use the ReceiveEvent matcher to assert on the changes, e.g.:
+//
+//	watcher, err := cp.Watch(deployments, "default", integration.WatchOptions{})
+//	Expect(err).NotTo(HaveOccurred())
+//	// ...
+//	Eventually(watcher).Should(integration.ReceiveEvent(integration.EventAdded))
+//	Eventually(watcher).Should(integration.ReceiveEvent(integration.EventModified,
+//		integration.HaveField("spec.replicas", 3)))
+type Watcher struct {
+	client    *RESTClient
+	gvr       GroupVersionResource
+	namespace string
+	opts      WatchOptions
+
+	mu      sync.Mutex
+	events  []WatchEvent
+	cursor  int
+	version string
+	err     error
+	// objects are the last states of the objects received so far, by
+	// namespace and name, to compare a list against.
+	objects map[string]Object
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// Watch starts watching the resource in the namespace, or in all namespaces
+// if namespace is empty. It returns an error if the APIServer rejects the
+// watch, e.g. because the user is not allowed to watch the resource.
+func (c *RESTClient) Watch(gvr GroupVersionResource, namespace string, opts WatchOptions) (*Watcher, error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	w := &Watcher{
+		client:    c,
+		gvr:       gvr,
+		namespace: namespace,
+		opts:      opts,
+		events:    []WatchEvent{},
+		version:   opts.ResourceVersion,
+		objects:   map[string]Object{},
+		cancel:    cancel,
+		done:      make(chan struct{}),
+	}
+
+	body, err := w.connect(ctx)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	go w.run(ctx, body)
+	return w, nil
+}
+
+// Watch starts watching the resource as the AdminUser, see RESTClient.Watch.
+// The Watcher is stopped when the ControlPlane stops, and by Reset and
+// Rollback.
+func (f *ControlPlane) Watch(gvr GroupVersionResource, namespace string, opts WatchOptions) (*Watcher, error) {
+	client, err := f.RESTClient()
+	if err != nil {
+		return nil, err
+	}
+	w, err := client.Watch(gvr, namespace, opts)
+	if err != nil {
+		return nil, err
+	}
+	f.mu.Lock()
+	f.watchers = append(f.watchers, w)
+	f.mu.Unlock()
+	return w, nil
+}
+
+// stopWatchers stops all Watchers started by Watch.
+func (f *ControlPlane) stopWatchers() {
+	f.mu.Lock()
+	watchers := f.watchers
+	f.watchers = nil
+	f.mu.Unlock()
+
+	for _, w := range watchers {
+		w.Stop()
+	}
+}
+
+// Events returns all events received so far.
+func (w *Watcher) Events() []WatchEvent {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return append([]WatchEvent{}, w.events...)
+}
+
+// Err returns the error which made the last watch fail, if any. The Watcher
+// keeps reconnecting nevertheless.
+func (w *Watcher) Err() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.err
+}
+
+// Stop stops watching and waits for the connection to be closed.
+func (w *Watcher) Stop() {
+	w.cancel()
+	<-w.done
+}
+
+// connect starts a watch, continuing after the last received change, or
+// after a list of the objects if there is none. The returned body is closed
+// when the context is cancelled.
+func (w *Watcher) connect(ctx context.Context) (io.ReadCloser, error) {
+	w.mu.Lock()
+	version := w.version
+	w.mu.Unlock()
+	if version == "" {
+		var err error
+		if version, err = w.relist(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	query := w.query()
+	query.Set("watch", "true")
+	query.Set("resourceVersion", version)
+
+	path := w.client.ResourcePath(w.gvr, w.namespace, "") + "?" + query.Encode()
+	res, err := w.client.send(ctx, "GET", path, "", nil)
+	if err != nil {
+		return nil, err
+	}
+	return res.Body, nil
+}
+
+// relist lists the objects, records an event for each one which is new,
+// changed or gone since it was last received, and returns the version of the
+// list.
+func (w *Watcher) relist(ctx context.Context) (string, error) {
+	path := w.client.ResourcePath(w.gvr, w.namespace, "")
+	if query := w.query(); len(query) > 0 {
+		path += "?" + query.Encode()
+	}
+	res, err := w.client.send(ctx, "GET", path, "", nil)
+	if err != nil {
+		return "", err
+	}
+	defer res.Body.Close()
+
+	list := Object{}
+	if err := json.NewDecoder(res.Body).Decode(&list); err != nil {
+		return "", err
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	listed := map[string]bool{}
+	for _, object := range list.Items() {
+		// The items of a list usually come without their kind.
+		if object.Kind() == "" {
+			object["apiVersion"] = list.APIVersion()
+			object["kind"] = strings.TrimSuffix(list.Kind(), "List")
+		}
+
+		key := watchKey(object)
+		listed[key] = true
+		known, ok := w.objects[key]
+		switch {
+		case !ok:
+			w.record(WatchEvent{Type: EventAdded, Object: object})
+		case known.stringField("metadata.resourceVersion") != object.stringField("metadata.resourceVersion"):
+			w.record(WatchEvent{Type: EventModified, Object: object})
+		}
+	}
+
+	gone := []string{}
+	for key := range w.objects {
+		if !listed[key] {
+			gone = append(gone, key)
+		}
+	}
+	sort.Strings(gone)
+	for _, key := range gone {
+		w.record(WatchEvent{Type: EventDeleted, Object: w.objects[key]})
+	}
+
+	w.version = list.stringField("metadata.resourceVersion")
+	return w.version, nil
+}
+
+// query returns the query parameters for the selectors of the WatchOptions.
+func (w *Watcher) query() url.Values {
+	query := url.Values{}
+	if w.opts.LabelSelector != "" {
+		query.Set("labelSelector", w.opts.LabelSelector)
+	}
+	if w.opts.FieldSelector != "" {
+		query.Set("fieldSelector", w.opts.FieldSelector)
+	}
+	return query
+}
+
+// record adds the event, and keeps track of the last state of its object.
+// The caller needs to hold the lock.
+func (w *Watcher) record(event WatchEvent) {
+	w.events = append(w.events, event)
+	if event.Type == EventDeleted {
+		delete(w.objects, watchKey(event.Object))
+	} else {
+		w.objects[watchKey(event.Object)] = event.Object
+	}
+}
+
+func watchKey(object Object) string {
+	return object.Namespace() + "/" + object.Name()
+}
+
+func (w *Watcher) run(ctx context.Context, body io.ReadCloser) {
+	defer close(w.done)
+
+	for {
+		w.setErr(w.receive(json.NewDecoder(body)))
+		body.Close()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(watchReconnectInterval):
+		}
+
+		var err error
+		body, err = w.connect(ctx)
+		for err != nil {
+			w.setErr(err)
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(watchReconnectInterval):
+			}
+			body, err = w.connect(ctx)
+		}
+	}
+}
+
+// receive decodes events until the stream ends.
+func (w *Watcher) receive(stream *json.Decoder) error {
+	for {
+		event := WatchEvent{}
+		if err := stream.Decode(&event); err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+
+		if event.Type == "ERROR" {
+			// The object is a Status. If the version we watched from is too
+			// old, we start over without one, and list the objects again.
+			if code, _ := event.Object.Field("code"); code == float64(410) {
+				w.mu.Lock()
+				w.version = ""
+				w.mu.Unlock()
+			}
+			message, _ := event.Object.Field("message")
+			return fmt.Errorf("watch of %s failed: %v", w.gvr, message)
+		}
+
+		w.mu.Lock()
+		w.record(event)
+		if version, ok := event.Object.Field("metadata.resourceVersion"); ok {
+			w.version = fmt.Sprintf("%v", version)
+		}
+		w.mu.Unlock()
+	}
+}
+
+func (w *Watcher) setErr(err error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.err = err
+}
+
+// receiveEvent looks for an event matching the matcher, after the last event
+// matched so far. If it finds one, it is the last matched event from then on.
+func (w *Watcher) receiveEvent(matches func(WatchEvent) (bool, error)) (bool, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	for i := w.cursor; i < len(w.events); i++ {
+		ok, err := matches(w.events[i])
+		if err != nil {
+			return false, err
+		}
+		if ok {
+			w.cursor = i + 1
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// pendingEvents returns the events received after the last matched event.
+func (w *Watcher) pendingEvents() []WatchEvent {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return append([]WatchEvent{}, w.events[w.cursor:]...)
+}
+
+// ReceiveEvent succeeds if actual, a *Watcher, has received an event of the
+// type (or of any type, if eventType is empty) with an object satisfying all
+// the matchers.
+//
+// Like gbytes.Say, it only looks at events received after the event it
+// matched the last time, so consecutive ReceiveEvent assertions check the
+// order of changes.
+func ReceiveEvent(eventType string, matchers ...types.GomegaMatcher) types.GomegaMatcher {
+	return &receiveEventMatcher{eventType: eventType, matchers: matchers}
+}
+
+type receiveEventMatcher struct {
+	eventType string
+	matchers  []types.GomegaMatcher
+}
+
+func (m *receiveEventMatcher) Match(actual interface{}) (bool, error) {
+	w, ok := actual.(*Watcher)
+	if !ok {
+		return false, fmt.Errorf("ReceiveEvent matcher expects a *Watcher. Got:\n%s", format.Object(actual, 1))
+	}
+
+	return w.receiveEvent(func(event WatchEvent) (bool, error) {
+		if m.eventType != "" && m.eventType != event.Type {
+			return false, nil
+		}
+		for _, matcher := range m.matchers {
+			if ok, err := matcher.Match(event.Object); !ok || err != nil {
+				return false, err
+			}
+		}
+		return true, nil
+	})
+}
+
+func (m *receiveEventMatcher) FailureMessage(actual interface{}) string {
+	return format.Message(summarizeWatchEvents(actual), "to receive an event", m.description())
+}
+
+func (m *receiveEventMatcher) NegatedFailureMessage(actual interface{}) string {
+	return format.Message(summarizeWatchEvents(actual), "not to receive an event", m.description())
+}
+
+func (m *receiveEventMatcher) description() string {
+	eventType := m.eventType
+	if eventType == "" {
+		eventType = "of any type"
+	}
+	if len(m.matchers) == 0 {
+		return eventType
+	}
+	return fmt.Sprintf("%s with an object matching %s", eventType, format.Object(m.matchers, 1))
+}
+
+// summarizeWatchEvents renders one line per event not matched yet, as the
+// full objects are too verbose to be useful in a failure message.
+func summarizeWatchEvents(actual interface{}) interface{} {
+	w, ok := actual.(*Watcher)
+	if !ok {
+		return actual
+	}
+
+	lines := []string{}
+	for _, event := range w.pendingEvents() {
+		lines = append(lines, event.String())
+	}
+	if err := w.Err(); err != nil {
+		lines = append(lines, fmt.Sprintf("(last watch error: %s)", err))
+	}
+	return strings.Join(lines, "\n")
+}
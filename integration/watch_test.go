@@ -0,0 +1,161 @@
+package integration
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/ghttp"
+)
+
+var _ = Describe("Watcher", func() {
+	var (
+		server  *ghttp.Server
+		client  *RESTClient
+		watcher *Watcher
+	)
+
+	pods := GroupVersionResource{Version: "v1", Resource: "pods"}
+
+	pod := func(name, resourceVersion string) string {
+		return `{"metadata": {"name": "` + name + `", "namespace": "default", "resourceVersion": "` + resourceVersion + `"}}`
+	}
+	event := func(eventType, name, resourceVersion string) string {
+		return `{"type": "` + eventType + `", "object": {"kind": "Pod", "metadata": {` +
+			`"name": "` + name + `", "namespace": "default", "resourceVersion": "` + resourceVersion + `"}}}` + "\n"
+	}
+	list := func(resourceVersion string, pods ...string) string {
+		return `{"apiVersion": "v1", "kind": "PodList", "metadata": {"resourceVersion": "` + resourceVersion + `"}, ` +
+			`"items": [` + strings.Join(pods, ", ") + `]}`
+	}
+
+	BeforeEach(func() {
+		watchReconnectInterval = 10 * time.Millisecond
+
+		server = ghttp.NewServer()
+		server.AllowUnhandledRequests = true
+		serverURL, err := url.Parse(server.URL())
+		Expect(err).NotTo(HaveOccurred())
+		client = &RESTClient{URL: *serverURL, httpClient: http.DefaultClient}
+	})
+	AfterEach(func() {
+		if watcher != nil {
+			watcher.Stop()
+			watcher = nil
+		}
+		server.Close()
+		watchReconnectInterval = 100 * time.Millisecond
+	})
+
+	It("receives events, in order, and reconnects where it left off", func() {
+		server.AppendHandlers(
+			ghttp.CombineHandlers(
+				ghttp.VerifyRequest("GET", "/api/v1/namespaces/default/pods", "labelSelector=app%3Dweb"),
+				ghttp.RespondWith(http.StatusOK, list("1", pod("a", "1"))),
+			),
+			ghttp.CombineHandlers(
+				ghttp.VerifyRequest("GET", "/api/v1/namespaces/default/pods", "labelSelector=app%3Dweb&resourceVersion=1&watch=true"),
+				ghttp.RespondWith(http.StatusOK, event(EventModified, "a", "2")),
+			),
+			ghttp.CombineHandlers(
+				ghttp.VerifyRequest("GET", "/api/v1/namespaces/default/pods", "labelSelector=app%3Dweb&resourceVersion=2&watch=true"),
+				ghttp.RespondWith(http.StatusOK, event(EventDeleted, "a", "3")),
+			),
+		)
+
+		var err error
+		watcher, err = client.Watch(pods, "default", WatchOptions{LabelSelector: "app=web"})
+		Expect(err).NotTo(HaveOccurred())
+
+		Eventually(watcher).Should(ReceiveEvent(EventAdded, HaveField("metadata.name", "a")))
+		Eventually(watcher).Should(ReceiveEvent(EventDeleted))
+		Expect(watcher).NotTo(ReceiveEvent(EventModified))
+
+		Expect(watcher.Events()).To(HaveLen(3))
+		Expect(watcher.Events()[0].String()).To(Equal("ADDED Pod default/a"))
+		Expect(watcher.Events()[1].String()).To(Equal("MODIFIED Pod default/a"))
+	})
+
+	It("starts over when the version it watches from is too old", func() {
+		server.AppendHandlers(
+			ghttp.CombineHandlers(
+				ghttp.VerifyRequest("GET", "/api/v1/pods", "resourceVersion=5&watch=true"),
+				ghttp.RespondWith(http.StatusOK, `{"type": "ERROR", "object": {"kind": "Status", "code": 410, "message": "too old resource version"}}`),
+			),
+			ghttp.CombineHandlers(
+				ghttp.VerifyRequest("GET", "/api/v1/pods", ""),
+				ghttp.RespondWith(http.StatusOK, list("7", pod("b", "7"))),
+			),
+			ghttp.CombineHandlers(
+				ghttp.VerifyRequest("GET", "/api/v1/pods", "resourceVersion=7&watch=true"),
+				ghttp.RespondWith(http.StatusOK, event(EventModified, "b", "8")),
+			),
+		)
+
+		var err error
+		watcher, err = client.Watch(pods, "", WatchOptions{ResourceVersion: "5"})
+		Expect(err).NotTo(HaveOccurred())
+
+		Eventually(watcher).Should(ReceiveEvent(EventAdded, HaveField("metadata.name", "b")))
+		Eventually(watcher).Should(ReceiveEvent(EventModified, HaveField("metadata.resourceVersion", "8")))
+	})
+
+	It("only receives what changed since the last event when it starts over", func() {
+		server.AppendHandlers(
+			ghttp.RespondWith(http.StatusOK, list("3", pod("a", "1"), pod("b", "2"), pod("c", "3"))),
+			ghttp.RespondWith(http.StatusOK, `{"type": "ERROR", "object": {"kind": "Status", "code": 410, "message": "too old resource version"}}`),
+			ghttp.RespondWith(http.StatusOK, list("9", pod("a", "1"), pod("c", "8"), pod("d", "9"))),
+		)
+
+		var err error
+		watcher, err = client.Watch(pods, "default", WatchOptions{})
+		Expect(err).NotTo(HaveOccurred())
+
+		summary := func() []string {
+			lines := []string{}
+			for _, event := range watcher.Events() {
+				lines = append(lines, event.String())
+			}
+			return lines
+		}
+		Eventually(summary).Should(Equal([]string{
+			"ADDED Pod default/a",
+			"ADDED Pod default/b",
+			"ADDED Pod default/c",
+			"MODIFIED Pod default/c",
+			"ADDED Pod default/d",
+			"DELETED Pod default/b",
+		}))
+		Consistently(summary).Should(HaveLen(6))
+	})
+
+	It("returns an error if the watch is rejected", func() {
+		server.AppendHandlers(ghttp.RespondWith(http.StatusForbidden, `{"kind": "Status", "reason": "Forbidden"}`))
+
+		_, err := client.Watch(pods, "default", WatchOptions{})
+		Expect(err).To(HaveOccurred())
+		Expect(err.(*StatusError).Reason).To(Equal("Forbidden"))
+	})
+
+	It("describes the events not matched yet on failure", func() {
+		server.AppendHandlers(
+			ghttp.RespondWith(http.StatusOK, list("1", pod("a", "1"))),
+			ghttp.RespondWith(http.StatusOK, ""),
+		)
+
+		var err error
+		watcher, err = client.Watch(pods, "default", WatchOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		Eventually(watcher.Events).Should(HaveLen(1))
+
+		matcher := ReceiveEvent(EventDeleted)
+		Expect(matcher.Match(watcher)).To(BeFalse())
+		Expect(matcher.FailureMessage(watcher)).To(ContainSubstring("ADDED Pod default/a"))
+
+		_, err = matcher.Match("not a watcher")
+		Expect(err).To(HaveOccurred())
+	})
+})